@@ -0,0 +1,74 @@
+package boiler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/steamguard"
+)
+
+const (
+	LoginAuthenticatorTerminal = "terminal"
+	LoginAuthenticatorFile     = "file"
+	LoginAuthenticatorEnv      = "env"
+)
+
+// authenticator returns the authenticator configured using [Config.LoginAuthenticator] and
+// [Config.LoginSharedSecretFile].
+// Returns nil if steamcmd should read from stdin.
+func (b *Boiler) authenticator() (steamcmd.Authenticator, error) {
+	var authenticator steamcmd.Authenticator
+	fileAuthenticator := steamcmd.FileAuthenticator{
+		PasswordFile:       b.config.LoginPasswordFile,
+		SteamGuardCodeFile: b.config.LoginSteamGuardCodeFile,
+	}
+	switch b.config.LoginAuthenticator {
+	case "":
+	case LoginAuthenticatorTerminal:
+		authenticator = steamcmd.TerminalAuthenticator{}
+	case LoginAuthenticatorFile:
+		authenticator = fileAuthenticator
+	case LoginAuthenticatorEnv:
+		authenticator = steamcmd.EnvAuthenticator{}
+	default:
+		return nil, fmt.Errorf("unknown LoginAuthenticator %s", b.config.LoginAuthenticator)
+	}
+
+	if b.config.LoginSharedSecretFile == "" {
+		return authenticator, nil
+	}
+
+	content, err := steamcmd.ReadSecretFile(b.config.LoginSharedSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading LoginSharedSecretFile: %w", err)
+	}
+	secret, err := steamguard.ParseSharedSecret(content)
+	if err != nil {
+		return nil, err
+	}
+	if authenticator == nil {
+		authenticator = fileAuthenticator
+	}
+
+	return sharedSecretAuthenticator{
+		Authenticator: authenticator,
+		sharedSecret:  secret,
+	}, nil
+}
+
+// sharedSecretAuthenticator generates the Steam Guard codes using the shared secret of a Steam
+// mobile authenticator. The password is supplied by the embedded authenticator.
+type sharedSecretAuthenticator struct {
+	steamcmd.Authenticator
+	sharedSecret []byte
+}
+
+func (a sharedSecretAuthenticator) SteamGuardCode(_ context.Context, _ string) (string, error) {
+	return a.GenerateSteamGuardCode()
+}
+
+func (a sharedSecretAuthenticator) GenerateSteamGuardCode() (string, error) {
+	return steamguard.Code(a.sharedSecret, time.Now()), nil
+}
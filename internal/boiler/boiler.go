@@ -25,6 +25,7 @@ type Boiler struct {
 	config      Config
 	db          *Database
 	gamesConfig GamesConfig
+	workshop    *steamworkshop.Client
 }
 
 type SyncOpts struct {
@@ -73,7 +74,10 @@ func (b *Boiler) changeWSItemCasing(toLower bool, items []WorkshopItemWithId) er
 			err := filecasing.MakeLowerCase(p, func(original string) {
 				changedPaths = append(changedPaths, filepath.Join(suffix, original))
 			})
-			if err != nil {
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				// The item failed to download
+			case err != nil:
 				return err
 			}
 		}
@@ -88,17 +92,7 @@ func (b *Boiler) changeWSItemCasing(toLower bool, items []WorkshopItemWithId) er
 		})
 		b.db.PathChanges = append(b.db.PathChanges, changedPaths...)
 	} else {
-		for _, p := range b.db.PathChanges {
-			skip := true
-			for _, item := range items {
-				if strings.HasPrefix(p, item.PathContentSuffix()) {
-					skip = false
-					break
-				}
-			}
-			if skip {
-				continue
-			}
+		for _, p := range b.pathChangesOf(items) {
 			err := filecasing.RestoreCase(basePath, p)
 			if err != nil {
 				return err
@@ -109,32 +103,133 @@ func (b *Boiler) changeWSItemCasing(toLower bool, items []WorkshopItemWithId) er
 	return nil
 }
 
+// pathChangesOf returns the path changes that belong to the workshop items.
+func (b *Boiler) pathChangesOf(items []WorkshopItemWithId) []string {
+	var result []string
+	for _, p := range b.db.PathChanges {
+		for _, item := range items {
+			if strings.HasPrefix(p, item.PathContentSuffix()) {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result
+}
+
 type DownloadOpts struct {
 	DownloadUpToDate bool
-	Logout           bool
-	Validate         bool
+	// If true, app_update is run for every game, even if the installed build is current.
+	ForceGameUpdate bool
+	Logout          bool
+	// The amount of steamcmd instances that download workshop items concurrently.
+	// If nil, [Config.DownloadParallelism] is used.
+	Parallelism *int
+	// The amount of times workshop items that failed to download are retried.
+	// If nil, [Config.DownloadRetries] is used.
+	Retries *int
+	// The time to wait before the first retry. Doubles with every subsequent retry.
+	// If 0, [Config.DownloadRetryBackoff] is used.
+	RetryBackoff time.Duration
+	Validate     bool
+	// If true, the games and workshop items that would be downloaded, the steamcmd scripts, and
+	// the changes to the file system are printed instead of performed. steamcmd is only run to
+	// retrieve the current builds of the games, nothing is downloaded or written.
+	DryRun bool
 }
 
-func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
+// Download downloads the games and the workshop items that are out of date.
+// If ctx is cancelled while steamcmd runs, the progress is saved: the workshop items that were
+// confirmed to be downloaded are marked as such, the file casing is updated, and the database is
+// saved before returning.
+func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) (resultErr error) {
+	retries := b.config.DownloadRetries
+	if opts.Retries != nil {
+		retries = *opts.Retries
+	}
+	parallelism := b.config.DownloadParallelism
+	if opts.Parallelism != nil {
+		parallelism = *opts.Parallelism
+	}
+	retryBackoff := opts.RetryBackoff
+	if retryBackoff == 0 {
+		retryBackoff = b.config.DownloadRetryBackoff
+	}
+	if retryBackoff == 0 {
+		retryBackoff = DefaultDownloadRetryBackoff
+	}
+
+	authenticator, err := b.authenticator()
+	if err != nil {
+		return err
+	}
+
 	downOpts := steamcmd.Opts{
+		Authenticator:         authenticator,
+		ConfigPaths:           b.steamCmdConfigPaths(),
+		ConfirmationTimeout:   b.config.LoginConfirmationTimeout,
 		LoginUsername:         b.config.LoginUsername,
 		InstallDir:            b.config.GamesDir,
 		DownloadGames:         make([]steamcmd.DownloadGameOpts, 0, len(b.gamesConfig)),
 		DownloadWorkshopItems: nil,
+		InactivityTimeout:     b.config.SteamCmdInactivityTimeout,
+		IsolatedHome:          b.config.SteamCmdIsolatedHome,
 		Logout:                opts.Logout,
+		RunTimeout:            b.config.SteamCmdRunTimeout,
 		SteamCmdPath:          b.config.SteamCmdPath,
+		WorkshopBatchMaxBytes: b.config.DownloadBatchMaxBytes,
+		WorkshopBatchMaxItems: b.config.DownloadBatchMaxItems,
 		WorkshopInstallDir:    filepath.Join(b.config.GamesDir, SteamWorkshopSubDir),
+		WorkshopParallelism:   parallelism,
 	}
 
-	var filenameCasingUpdates []WorkshopItemWithId
+	downOpts.OnEvent = func(event steamcmd.Event) {
+		switch event.(type) {
+		case steamcmd.RateLimitEvent:
+			log.Printf("WARNING: steamcmd reports that the rate limit has been exceeded")
+		}
+	}
 
-	for _, gameConfig := range b.gamesConfig {
+	checkBuilds := !opts.Validate && !opts.ForceGameUpdate && len(b.gamesConfig) > 0
+	if opts.Logout && (checkBuilds || (!opts.DryRun && retries > 0)) &&
+		b.config.LoginUsername != "" && !b.config.SteamCmdIsolatedHome {
+		// Logging out after every steamcmd invocation would require logging in again for the
+		// download and every retry.
+		downOpts.Logout = false
+		defer func() {
+			err := b.Logout(context.WithoutCancel(ctx))
+			if err != nil {
+				resultErr = errors.Join(resultErr, fmt.Errorf("error logging out: %w", err))
+			}
+		}()
+	}
+
+	games := b.gamesConfig
+	if checkBuilds {
+		games, err = b.outdatedGames(ctx, authenticator, opts.DryRun)
+		if err != nil {
+			return err
+		}
+	}
+	for _, gameConfig := range games {
+		betaPassword, err := gameConfig.betaPassword()
+		if err != nil {
+			return err
+		}
 		downOpts.DownloadGames = append(downOpts.DownloadGames, steamcmd.DownloadGameOpts{
-			Id:         gameConfig.Id,
-			BetaBranch: gameConfig.BetaBranch,
-			Name:       gameConfig.Name,
-			Validate:   opts.Validate,
+			Id:           gameConfig.Id,
+			BetaBranch:   gameConfig.BetaBranch,
+			BetaPassword: betaPassword,
+			Depots:       toDepotManifests(gameConfig.Depots),
+			Name:         gameConfig.Name,
+			Platform:     gameConfig.Platform,
+			Validate:     opts.Validate,
 		})
+	}
+
+	var filenameCasingUpdates []WorkshopItemWithId
+
+	for _, gameConfig := range b.gamesConfig {
 		ids, err := gameConfig.GetWorkshopItemsOrdered(b.db)
 		if err != nil {
 			return err
@@ -143,6 +238,15 @@ func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
 			if !opts.DownloadUpToDate && item.LastDownloaded.After(item.TimeUpdated) {
 				continue
 			}
+			if item.Availability != AvailabilityAvailable {
+				log.Printf(
+					"Skipping workshop item %d (%s), it is %s",
+					item.Id,
+					item.Title,
+					item.Availability,
+				)
+				continue
+			}
 
 			if gameConfig.MakeWorkshopItemsLowercase {
 				filenameCasingUpdates = append(filenameCasingUpdates, item)
@@ -153,6 +257,7 @@ func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
 				steamcmd.DownloadWorkshopItemOpts{
 					GameId:         gameConfig.WorkshopAppId,
 					WorkshopItemId: item.Id,
+					Size:           item.FileSize,
 				},
 			)
 		}
@@ -160,30 +265,69 @@ func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
 
 	log.Printf("%d games will be updated", len(downOpts.DownloadGames))
 	log.Printf("%d workshop items will be updated", len(downOpts.DownloadWorkshopItems))
+	if opts.DryRun {
+		return b.printPlan(downOpts, filenameCasingUpdates)
+	}
 
-	err := b.changeWSItemCasing(false, filenameCasingUpdates)
+	err = b.changeWSItemCasing(false, filenameCasingUpdates)
 	switch {
 	case errors.Is(err, os.ErrNotExist):
 	case err != nil:
 		return fmt.Errorf("error restoring workshop items file casing: %w", err)
 	}
-	err = steamcmd.Exec(ctx, downOpts)
-	if err != nil {
-		caseErr := b.changeWSItemCasing(true, filenameCasingUpdates)
-		return errors.Join(err, caseErr)
+	execErr := steamcmd.Exec(ctx, downOpts)
+	// The workshop items are verified regardless of other errors. Items that are not reported as
+	// failed have been downloaded.
+	var wsErr *steamcmd.WorkshopDownloadError
+	if errors.As(execErr, &wsErr) && isOnlyWorkshopDownloadError(execErr) {
+		var retryErr error
+		wsErr, retryErr = b.retryWorkshopItems(ctx, downOpts, wsErr, retries, retryBackoff)
+		execErr = nil
+		if wsErr != nil {
+			execErr = errors.Join(wsErr, retryErr)
+		}
+	}
+
+	if ctx.Err() != nil {
+		log.Printf("Download cancelled, saving progress")
+	}
+
+	failedItems := make(map[uint64]struct{})
+	if wsErr != nil {
+		for _, failure := range wsErr.Failures {
+			failedItems[failure.WorkshopItemId] = struct{}{}
+			log.Printf(
+				"Workshop item %d (%s) failed to download: %s",
+				failure.WorkshopItemId,
+				b.db.WorkshopItems[failure.WorkshopItemId].Title,
+				failure.Reason,
+			)
+		}
 	}
 
 	for _, downItem := range downOpts.DownloadWorkshopItems {
+		if _, failed := failedItems[downItem.WorkshopItemId]; failed {
+			continue
+		}
 		item := b.db.WorkshopItems[downItem.WorkshopItemId]
 		item.LastDownloaded = time.Now()
 		b.db.WorkshopItems[downItem.WorkshopItemId] = item
 	}
 
-	resultErr := b.changeWSItemCasing(true, filenameCasingUpdates)
+	resultErr = b.changeWSItemCasing(true, filenameCasingUpdates)
 	if resultErr != nil {
 		resultErr = fmt.Errorf("error changing workshop items file casing to lower: %w", resultErr)
 	}
-	resultErr = errors.Join(b.Save(), b.createSymlinks(), resultErr)
+	if execErr == nil || isOnlyWorkshopDownloadError(execErr) {
+		err = b.recordInstalledBuilds(b.gamesConfig)
+		if err != nil {
+			resultErr = errors.Join(resultErr, fmt.Errorf("error recording installed builds: %w", err))
+		}
+	}
+	resultErr = errors.Join(execErr, b.Save(), b.createSymlinks(), resultErr)
+	if execErr != nil && !isOnlyWorkshopDownloadError(execErr) {
+		return resultErr
+	}
 
 	for _, gameConfig := range b.gamesConfig {
 		if gameConfig.PostInstall == "" {
@@ -193,7 +337,7 @@ func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
 		cmd := exec.CommandContext(ctx, gameConfig.PostInstall)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
-		err = cmd.Run()
+		err := cmd.Run()
 		if err != nil {
 			resultErr = errors.Join(resultErr, fmt.Errorf(
 				"error running postinstall %s: %w",
@@ -206,16 +350,110 @@ func (b *Boiler) Download(ctx context.Context, opts DownloadOpts) error {
 	return resultErr
 }
 
+// retryWorkshopItems downloads the failed workshop items again in new steamcmd sessions until
+// they succeed or the retries are exhausted.
+// Returns the workshop items that still failed, if any, and an error if a retry failed for
+// another reason than workshop items failing to download. In that case, retrying stops.
+func (b *Boiler) retryWorkshopItems(
+	ctx context.Context,
+	downOpts steamcmd.Opts,
+	wsErr *steamcmd.WorkshopDownloadError,
+	retries int,
+	backoff time.Duration,
+) (*steamcmd.WorkshopDownloadError, error) {
+	for attempt := 1; attempt <= retries && wsErr != nil; attempt++ {
+		log.Printf(
+			"Retrying %d failed workshop items in %s (attempt %d of %d)",
+			len(wsErr.Failures),
+			backoff,
+			attempt,
+			retries,
+		)
+		select {
+		case <-ctx.Done():
+			return wsErr, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		retryOpts := downOpts
+		retryOpts.DownloadGames = nil
+		retryOpts.DownloadWorkshopItems = make(
+			[]steamcmd.DownloadWorkshopItemOpts,
+			0,
+			len(wsErr.Failures),
+		)
+		for _, failure := range wsErr.Failures {
+			retryOpts.DownloadWorkshopItems = append(
+				retryOpts.DownloadWorkshopItems,
+				steamcmd.DownloadWorkshopItemOpts{
+					GameId:         failure.GameId,
+					WorkshopItemId: failure.WorkshopItemId,
+					Size:           b.db.WorkshopItems[failure.WorkshopItemId].FileSize,
+				},
+			)
+		}
+
+		err := steamcmd.Exec(ctx, retryOpts)
+		if err == nil {
+			return nil, nil
+		}
+		var retryErr *steamcmd.WorkshopDownloadError
+		if !errors.As(err, &retryErr) {
+			return wsErr, fmt.Errorf("error retrying workshop items: %w", err)
+		}
+		wsErr = retryErr
+		if !isOnlyWorkshopDownloadError(err) {
+			return wsErr, fmt.Errorf("error retrying workshop items: %w", err)
+		}
+	}
+
+	return wsErr, nil
+}
+
+// isOnlyWorkshopDownloadError returns true if err only reports workshop items that failed to
+// download.
+func isOnlyWorkshopDownloadError(err error) bool {
+	_, ok := err.(*steamcmd.WorkshopDownloadError)
+	return ok
+}
+
 func (b *Boiler) createSymlinks() error {
+	links, err := b.symlinks(nil)
+	if err != nil {
+		return err
+	}
+
 	var resultErr error
+	for _, link := range links {
+		err := overwriteSymlink(link.target, link.name)
+		switch {
+		case errors.Is(err, os.ErrExist):
+		case err != nil:
+			resultErr = errors.Join(resultErr, err)
+		}
+	}
+
+	return resultErr
+}
+
+type symlink struct {
+	target string
+	name   string
+}
+
+// symlinks returns the symlinks from the game directories to the workshop items of the games that
+// have downloaded workshop items. Items in downloading are considered downloaded.
+func (b *Boiler) symlinks(downloading map[uint64]struct{}) ([]symlink, error) {
+	var result []symlink
 	for _, game := range b.gamesConfig {
 		skip := true
 		for _, idc := range game.WorkshopItems {
 			workshopItem, ok := b.db.WorkshopItems[idc.Id]
 			if !ok {
-				return fmt.Errorf("workshop item %d not found", idc.Id)
+				return nil, fmt.Errorf("workshop item %d not found", idc.Id)
 			}
-			if workshopItem.LastDownloaded.IsZero() {
+			if _, ok := downloading[idc.Id]; !ok && workshopItem.LastDownloaded.IsZero() {
 				continue
 			}
 			skip = false
@@ -224,25 +462,25 @@ func (b *Boiler) createSymlinks() error {
 		if skip {
 			continue
 		}
-		err := overwriteSymlink(
-			filepath.Join(
+		result = append(result, symlink{
+			target: filepath.Join(
 				b.config.GamesDir,
 				SteamWorkshopItemPrefix,
 				strconv.Itoa(game.WorkshopAppId),
 			),
-			filepath.Join(b.config.GamesDir, game.Name, "mods"),
-		)
-		switch {
-		case errors.Is(err, os.ErrExist):
-		case err != nil:
-			resultErr = errors.Join(resultErr, err)
-		}
+			name: filepath.Join(b.config.GamesDir, game.Name, "mods"),
+		})
 	}
 
-	return resultErr
+	return result, nil
 }
 
 type UpdateOpts struct {
+	// If true, the database is updated in memory but not saved.
+	DryRun bool
+	// If true, an error is returned after the database is updated if any of the workshop items
+	// is unavailable, e.g. because it has been removed.
+	FailOnUnavailable bool
 }
 
 // UpdateDatabase updates the database based on the games configuration.
@@ -265,7 +503,7 @@ func (b *Boiler) UpdateDatabase(ctx context.Context, opts UpdateOpts) error {
 		nextCollections = make(map[uint64]struct{})
 		for _, keys := range batchMapKeys(collections, 100) {
 			log.Printf("Getting info of %d collections", len(keys))
-			result, err := steamworkshop.CollectionDetailsApi(ctx, keys...)
+			result, err := b.workshop.CollectionDetailsApi(ctx, keys...)
 			if err != nil {
 				return err
 			}
@@ -330,6 +568,8 @@ func (b *Boiler) UpdateDatabase(ctx context.Context, opts UpdateOpts) error {
 	}
 
 	workshopItemsSeen := make(map[uint64]struct{})
+	var unavailable []uint64
+	var updated []uint64
 	for {
 		if len(nextWorkshopItems) == 0 {
 			break
@@ -337,10 +577,15 @@ func (b *Boiler) UpdateDatabase(ctx context.Context, opts UpdateOpts) error {
 		workshopItems := nextWorkshopItems
 		nextWorkshopItems = make(map[uint64]struct{})
 		updateRequirements := make(map[uint64]struct{})
+		// Items of which only the required apps are retrieved from their page, since the Web API
+		// returns their required items but not their required apps.
+		updateRequiredApps := make(map[uint64]struct{})
+		// The required items as reported by the Web API. Retrieved next, unless already seen.
+		var knownRequirements []uint64
 
 		for _, ids := range batchMapKeys(workshopItems, 100) {
 			log.Printf("Getting info of %d workshop items", len(ids))
-			fileDetails, err := steamworkshop.FileDetailsApi(ctx, ids...)
+			fileDetails, requirements, err := b.workshopItemDetails(ctx, ids)
 			if err != nil {
 				return err
 			}
@@ -348,30 +593,98 @@ func (b *Boiler) UpdateDatabase(ctx context.Context, opts UpdateOpts) error {
 			for _, detail := range fileDetails {
 				existing, alreadyExists := b.db.WorkshopItems[detail.Id]
 				newItem := WorkshopItem{
+					BanReason:      detail.BanReason,
+					ContentFile:    detail.ContentFile,
+					Creator:        detail.Creator,
 					CreatorAppId:   detail.CreatorAppId,
+					Description:    detail.Description,
+					FileSize:       detail.FileSize,
 					LastDownloaded: time.Time{},
 					LastRefreshed:  time.Now(),
+					PreviewUrl:     detail.PreviewUrl,
 					Requires:       nil,
+					Subscriptions:  detail.Subscriptions,
+					Tags:           detail.Tags,
 					TimeCreated:    detail.TimeCreated,
 					TimeUpdated:    detail.TimeUpdated,
 					Title:          detail.Title,
+					Visibility:     detail.Visibility,
 				}
 				if alreadyExists {
 					newItem.LastDownloaded = existing.LastDownloaded
 					newItem.Requires = existing.Requires
+					newItem.RequiredApps = existing.RequiredApps
+					newItem.Changelog = existing.Changelog
+				}
+				if detail.Result != steamworkshop.ResultOk {
+					// Steam returns no details, keep the last known ones.
+					newItem = existing
+					newItem.LastRefreshed = time.Now()
+				}
+				availability, known := availabilityOf(detail)
+				switch {
+				case !known && !alreadyExists:
+					// Without details, the item cannot be downloaded.
+					availability = AvailabilityUnknown
+					log.Printf(
+						"WARNING: Steam returned %s for new workshop item %d, its details are unknown",
+						detail.Result,
+						detail.Id,
+					)
+				case !known:
+					availability = existing.Availability
+					log.Printf(
+						"WARNING: Steam returned %s for workshop item %d (%s), keeping its last known availability",
+						detail.Result,
+						detail.Id,
+						existing.Title,
+					)
 				}
-				if !alreadyExists || existing.TimeUpdated.Before(detail.TimeUpdated) {
+				newItem.Availability = availability
+				switch {
+				case newItem.Availability != AvailabilityAvailable:
+					unavailable = append(unavailable, detail.Id)
+					reason := string(newItem.Availability)
+					if detail.BanReason != "" {
+						reason += ": " + detail.BanReason
+					}
+					log.Printf(
+						"WARNING: workshop item %d (%s) is unavailable (%s)",
+						detail.Id,
+						newItem.Title,
+						reason,
+					)
+				case !known:
+					// Nothing new is known about the item, it is checked again by the next update.
+				case requirements != nil:
+					newItem.Requires = requirements[detail.Id]
+					knownRequirements = append(knownRequirements, newItem.Requires...)
+					if !alreadyExists || existing.TimeUpdated.Before(detail.TimeUpdated) {
+						updateRequiredApps[detail.Id] = struct{}{}
+					}
+				case !alreadyExists || existing.TimeUpdated.Before(detail.TimeUpdated):
 					updateRequirements[detail.Id] = struct{}{}
 				}
+				if newItem.Availability == AvailabilityAvailable &&
+					alreadyExists &&
+					existing.TimeUpdated.Before(detail.TimeUpdated) {
+					updated = append(updated, detail.Id)
+				}
 
 				b.db.WorkshopItems[detail.Id] = newItem
 				workshopItemsSeen[detail.Id] = struct{}{}
 			}
 		}
 
+		for _, requiredId := range knownRequirements {
+			if _, ok := workshopItemsSeen[requiredId]; !ok {
+				nextWorkshopItems[requiredId] = struct{}{}
+			}
+		}
+
 		for workshopId := range updateRequirements {
 			log.Printf("Getting dependencies of workshop item %d", workshopId)
-			fileDetails, err := steamworkshop.GetFileDetailsWeb(ctx, workshopId)
+			fileDetails, err := b.workshop.GetFileDetailsWeb(ctx, workshopId)
 			if err != nil {
 				return err
 			}
@@ -391,19 +704,137 @@ func (b *Boiler) UpdateDatabase(ctx context.Context, opts UpdateOpts) error {
 					nextWorkshopItems[requiredItem.Id] = struct{}{}
 				}
 			}
+			item.RequiredApps = requiredAppsOf(fileDetails)
+
+			b.db.WorkshopItems[workshopId] = item
+		}
 
+		for workshopId := range updateRequiredApps {
+			log.Printf("Getting required apps of workshop item %d", workshopId)
+			fileDetails, err := b.workshop.GetFileDetailsWeb(ctx, workshopId)
+			if err != nil {
+				return err
+			}
+
+			item, ok := b.db.WorkshopItems[workshopId]
+			if !ok {
+				return fmt.Errorf(
+					"workshop item %d not in database on required apps update",
+					workshopId,
+				)
+			}
+			item.RequiredApps = requiredAppsOf(fileDetails)
 			b.db.WorkshopItems[workshopId] = item
 		}
 	}
 
-	err := b.Save()
+	err := b.updateChangelogs(ctx, updated)
 	if err != nil {
 		return err
 	}
 
+	b.warnMissingRequiredApps()
+
+	if !opts.DryRun {
+		err = b.Save()
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.FailOnUnavailable && len(unavailable) > 0 {
+		slices.Sort(unavailable)
+		return fmt.Errorf("%d workshop item(s) are unavailable: %v", len(unavailable), unavailable)
+	}
+
 	return nil
 }
 
+// requiredAppsOf returns the required apps listed on the page of a workshop item.
+func requiredAppsOf(fileDetails steamworkshop.FileDetailsWeb) []RequiredApp {
+	var result []RequiredApp
+	for _, requiredApp := range fileDetails.RequiredApps {
+		result = append(result, RequiredApp{
+			Id:   requiredApp.Id,
+			Name: requiredApp.Name,
+		})
+	}
+	return result
+}
+
+// warnMissingRequiredApps logs a warning for every app that is required by a workshop item of a
+// game, but that is neither the game nor a DLC contained by its branch.
+func (b *Boiler) warnMissingRequiredApps() {
+	for _, game := range b.gamesConfig {
+		items, err := game.GetWorkshopItemsOrdered(b.db)
+		if err != nil {
+			log.Printf("Failed to check the required apps of %s: %v", game.Name, err)
+			continue
+		}
+		branchDlc, branchDlcKnown := b.db.BranchDlc[game.Name]
+		dlcUnknown := false
+		for _, item := range items {
+			for _, app := range item.RequiredApps {
+				if game.ProvidesApp(app.Id, branchDlc) {
+					continue
+				}
+				if !branchDlcKnown {
+					dlcUnknown = true
+					continue
+				}
+				log.Printf(
+					"WARNING: workshop item %d (%s) of %s requires app %d (%s), which is not "+
+						"contained by branch %s",
+					item.Id,
+					item.Title,
+					game.Name,
+					app.Id,
+					app.Name,
+					branchName(game.BetaBranch),
+				)
+			}
+		}
+		if dlcUnknown {
+			log.Printf(
+				"The DLC of branch %s of %s are not known yet, the required apps of its workshop "+
+					"items are checked once the game has been updated",
+				branchName(game.BetaBranch),
+				game.Name,
+			)
+		}
+	}
+}
+
+// workshopItemDetails returns the details of the workshop items. If a Steam Web API key is
+// configured, the required items of every available workshop item are returned as well.
+// Otherwise, requirements is nil and the required items must be retrieved from the pages of the
+// workshop items.
+func (b *Boiler) workshopItemDetails(
+	ctx context.Context,
+	ids []uint64,
+) (details []steamworkshop.FileDetailApi, requirements map[uint64][]uint64, err error) {
+	if !b.workshop.HasApiKey() {
+		details, err = b.workshop.FileDetailsApi(ctx, ids...)
+		return details, nil, err
+	}
+
+	published, err := b.workshop.PublishedFileDetailsApi(ctx, ids...)
+	if err != nil {
+		return nil, nil, err
+	}
+	details = make([]steamworkshop.FileDetailApi, 0, len(published))
+	requirements = make(map[uint64][]uint64, len(published))
+	for _, detail := range published {
+		details = append(details, detail.FileDetailApi)
+		var requires []uint64
+		for _, child := range detail.Children {
+			requires = append(requires, child.Id)
+		}
+		requirements[detail.Id] = requires
+	}
+	return details, requirements, nil
+}
+
 func (b *Boiler) GetWorkshopItemsForGame(gameName string) ([]WorkshopItemWithId, error) {
 	for _, config := range b.gamesConfig {
 		if config.Name != gameName {
@@ -464,7 +895,21 @@ func (b *Boiler) GetGames() []string {
 }
 
 func (b *Boiler) Logout(ctx context.Context) error {
-	return steamcmd.LogOutUser(ctx, b.config.SteamCmdPath, b.config.LoginUsername)
+	return steamcmd.LogOutUser(
+		ctx,
+		b.config.SteamCmdPath,
+		b.config.LoginUsername,
+		b.steamCmdConfigPaths(),
+	)
+}
+
+// steamCmdConfigPaths returns the config.vdf files to check for cached credentials.
+// Returns nil to use the defaults.
+func (b *Boiler) steamCmdConfigPaths() []string {
+	if b.config.SteamCmdConfigPath == "" {
+		return nil
+	}
+	return []string{b.config.SteamCmdConfigPath}
 }
 
 func (b *Boiler) getRequiredWorkshopIds(workshopIds []uint64) []uint64 {
@@ -485,8 +930,10 @@ func (b *Boiler) loadDatabase() error {
 	switch {
 	case errors.Is(err, os.ErrNotExist):
 		b.db = &Database{
-			Collections:   map[uint64]Collection{},
-			WorkshopItems: map[uint64]WorkshopItem{},
+			Collections:     map[uint64]Collection{},
+			InstalledBuilds: map[string][]InstalledBuild{},
+			BranchDlc:       map[string][]int{},
+			WorkshopItems:   map[uint64]WorkshopItem{},
 		}
 		return nil
 	case err != nil:
@@ -505,6 +952,12 @@ func (b *Boiler) loadDatabase() error {
 	if b.db.WorkshopItems == nil {
 		b.db.WorkshopItems = map[uint64]WorkshopItem{}
 	}
+	if b.db.InstalledBuilds == nil {
+		b.db.InstalledBuilds = map[string][]InstalledBuild{}
+	}
+	if b.db.BranchDlc == nil {
+		b.db.BranchDlc = map[string][]int{}
+	}
 	return nil
 }
 
@@ -561,8 +1014,17 @@ func FromConfig(config Config) (*Boiler, error) {
 		return nil, fmt.Errorf("config.GamesConfPath is not set")
 	}
 	b := &Boiler{config: config}
+	_, err := b.authenticator()
+	if err != nil {
+		return nil, err
+	}
 
-	err := b.loadDatabase()
+	b.workshop, err = config.workshopClient()
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.loadDatabase()
 	if err != nil {
 		return nil, err
 	}
@@ -571,6 +1033,10 @@ func FromConfig(config Config) (*Boiler, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = b.gamesConfig.validate()
+	if err != nil {
+		return nil, err
+	}
 
 	return b, nil
 }
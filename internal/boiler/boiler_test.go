@@ -2,6 +2,9 @@ package boiler_test
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,9 +15,14 @@ import (
 )
 
 func TestBoilerConfig(t *testing.T) {
+	// Save writes to the files, copy them so that the testdata remains untouched.
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS("testdata")); err != nil {
+		t.Fatal(err)
+	}
 	b, err := boiler.FromConfig(boiler.Config{
-		DatabasePath:  "testdata/db.json",
-		GamesConfPath: "testdata/games.json",
+		DatabasePath:  filepath.Join(dir, "db.json"),
+		GamesConfPath: filepath.Join(dir, "games.json"),
 		GamesDir:      "/dev/null",
 		LoginUsername: "anonymous",
 		SteamCmdPath:  "/usr/bin/false",
@@ -33,6 +41,19 @@ func TestDatabaseJsonRoundtrip(t *testing.T) {
 				},
 			},
 		},
+		InstalledBuilds: map[string][]boiler.InstalledBuild{
+			"Arma3": {
+				{
+					BuildId:     "14521731",
+					Depots:      []boiler.Depot{{DepotId: 233781, ManifestId: 6183564618733453744}},
+					InstalledAt: time.Unix(1758384867, 0).UTC(),
+				},
+			},
+		},
+		BranchDlc: map[string][]int{
+			"Arma3": {1042220, 1681170},
+		},
+		ChangelogsRetrieved: time.Unix(1758384900, 0).UTC(),
 		PathChanges: []string{
 			"10700/463939057/There/Be/Uppercase.txt",
 		},
@@ -40,9 +61,26 @@ func TestDatabaseJsonRoundtrip(t *testing.T) {
 			463939057: {
 				CreatorAppId: 10700,
 				Requires:     []uint64{120, 2},
-				TimeCreated:  time.Unix(1758384867, 0),
-				TimeUpdated:  time.Unix(1758384867, 0),
+				TimeCreated:  time.Unix(1758384867, 0).UTC(),
+				TimeUpdated:  time.Unix(1758384867, 0).UTC(),
 				Title:        "Hello",
+				Availability: boiler.AvailabilityBanned,
+				BanReason:    "Copyright",
+				ContentFile:  3524971499552554428,
+				Creator:      76561198194647182,
+				Description:  "[h1]Hello[/h1]",
+				Tags:         []string{"Mod", "x64"},
+				Visibility:   steamworkshop.VisibilityUnlisted,
+				RequiredApps: []boiler.RequiredApp{
+					{Id: 1681170, Name: "Arma 3 Creator DLC: Western Sahara"},
+				},
+				Changelog: []boiler.ChangelogEntry{
+					{
+						Time:        time.Unix(1758384867, 0).UTC(),
+						Description: "Fixed things",
+						Retrieved:   time.Unix(1758384900, 0).UTC(),
+					},
+				},
 			},
 		},
 	}
@@ -82,6 +120,9 @@ func TestGamesConfigJsonRoundtrip(t *testing.T) {
 			WorkshopCollections: []boiler.IdWithComment{
 				{18474846, "some collection"},
 			},
+			Depots: []boiler.Depot{
+				{DepotId: 233781, ManifestId: 6183564618733453744},
+			},
 		},
 	}
 	var outJson bytes.Buffer
@@ -166,3 +207,116 @@ func TestGamesConfig_UpdateComments(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestConfigJsonDuration(t *testing.T) {
+	var config boiler.Config
+	err := json.Unmarshal([]byte(`{"DownloadRetries":3,"DownloadRetryBackoff":"1m30s"}`), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, config.DownloadRetries)
+	assert.Equal(t, 90*time.Second, config.DownloadRetryBackoff)
+}
+
+func TestFromConfig_InvalidPlatform(t *testing.T) {
+	gamesConfPath := filepath.Join(t.TempDir(), "games.json")
+	gamesConf := `[{"Name":"Arma3","Id":233780,"Platform":"amiga"}]`
+	err := os.WriteFile(gamesConfPath, []byte(gamesConf), 0600)
+	assert.NoError(t, err)
+
+	_, err = boiler.FromConfig(boiler.Config{
+		DatabasePath:  filepath.Join(t.TempDir(), "db.json"),
+		GamesConfPath: gamesConfPath,
+	})
+	assert.ErrorContains(t, err, "unknown Platform amiga of game Arma3")
+}
+
+func TestDownload_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "db.json")
+	gamesConfPath := filepath.Join(dir, "games.json")
+	gamesConf := `[{"Name":"Arma3","Id":233780,"PostInstall":"/usr/bin/false"}]`
+	assert.NoError(t, os.WriteFile(gamesConfPath, []byte(gamesConf), 0600))
+
+	b, err := boiler.FromConfig(boiler.Config{
+		DatabasePath:  dbPath,
+		GamesConfPath: gamesConfPath,
+		GamesDir:      filepath.Join(dir, "games"),
+		SteamCmdPath:  "/usr/bin/false",
+	})
+	assert.NoError(t, err)
+
+	err = b.Download(context.Background(), boiler.DownloadOpts{DryRun: true})
+	assert.NoError(t, err)
+	assert.NoFileExists(t, dbPath)
+	assert.NoDirExists(t, filepath.Join(dir, "games"))
+}
+
+func TestDownload_CancelSavesProgress(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "db.json")
+	gamesConfPath := filepath.Join(dir, "games.json")
+	gamesDir := filepath.Join(dir, "games")
+	gamesConf := `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"MakeWorkshopItemsLowercase": true,
+		"WorkshopItems": [["1", "downloaded"], ["2", "cancelled"]]
+	}]`
+	db := `{"WorkshopItems":{
+		"1":{"CreatorAppId":107410,"Title":"downloaded"},
+		"2":{"CreatorAppId":107410,"Title":"cancelled"}
+	}}`
+	assert.NoError(t, os.WriteFile(gamesConfPath, []byte(gamesConf), 0600))
+	assert.NoError(t, os.WriteFile(dbPath, []byte(db), 0600))
+	assert.NoError(t, os.Mkdir(gamesDir, 0700))
+
+	// Downloads the first workshop item and then hangs on the second.
+	manifestPath := filepath.Join(
+		gamesDir,
+		boiler.SteamWorkshopSubDir,
+		"steamapps/workshop/appworkshop_107410.acf",
+	)
+	contentDir := filepath.Join(gamesDir, boiler.SteamWorkshopItemPrefix, "107410/1")
+	steamcmdPath := filepath.Join(dir, "steamcmd")
+	assert.NoError(t, os.WriteFile(steamcmdPath, []byte(`#!/bin/sh
+grep -q workshop_download_item "$2" || exit 0
+mkdir -p '`+contentDir+`/Addons'
+touch '`+contentDir+`/Addons/Mod.PBO'
+printf '"AppWorkshop"\n{\n"WorkshopItemsInstalled"\n{\n"1"\n{\n}\n}\n}\n' > '`+manifestPath+`'
+echo 'Success. Downloaded item 1 to "`+contentDir+`" (10 bytes)'
+exec sleep 10
+`), 0700))
+
+	b, err := boiler.FromConfig(boiler.Config{
+		DatabasePath:  dbPath,
+		GamesConfPath: gamesConfPath,
+		GamesDir:      gamesDir,
+		SteamCmdPath:  steamcmdPath,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			if _, err := os.Stat(manifestPath); err == nil {
+				cancel()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	err = b.Download(ctx, boiler.DownloadOpts{ForceGameUpdate: true})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second, "steamcmd was not stopped")
+
+	var saved boiler.Database
+	dbJson, err := os.ReadFile(dbPath)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(dbJson, &saved))
+	assert.False(t, saved.WorkshopItems[1].LastDownloaded.IsZero())
+	assert.True(t, saved.WorkshopItems[2].LastDownloaded.IsZero())
+	assert.FileExists(t, filepath.Join(contentDir, "addons/mod.pbo"))
+}
@@ -0,0 +1,292 @@
+package boiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+)
+
+// maxInstalledBuilds is the amount of builds that are remembered per game.
+const maxInstalledBuilds = 10
+
+// outdatedGames returns the games of which the installed build differs from the current build of
+// their branch, or from their pinned depots. If the current builds cannot be retrieved, all games
+// that are not pinned are returned.
+// During a dry run, the current builds are retrieved but the database is not changed.
+func (b *Boiler) outdatedGames(
+	ctx context.Context,
+	authenticator steamcmd.Authenticator,
+	dryRun bool,
+) (GamesConfig, error) {
+	var appIds []int
+	for _, game := range b.gamesConfig {
+		if len(game.Depots) == 0 {
+			appIds = append(appIds, game.Id)
+		}
+	}
+
+	var appInfo map[int]vdf.Object
+	var appInfoErr error
+	if len(appIds) > 0 {
+		appInfo, appInfoErr = steamcmd.AppInfo(ctx, steamcmd.AppInfoOpts{
+			AppIds:              appIds,
+			LoginUsername:       b.config.LoginUsername,
+			SteamCmdPath:        b.config.SteamCmdPath,
+			IsolatedHome:        b.config.SteamCmdIsolatedHome,
+			Authenticator:       authenticator,
+			ConfirmationTimeout: b.config.LoginConfirmationTimeout,
+			RunTimeout:          b.config.SteamCmdRunTimeout,
+			InactivityTimeout:   b.config.SteamCmdInactivityTimeout,
+		})
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case appInfoErr != nil:
+			log.Printf(
+				"WARNING: failed to retrieve the current builds, all games will be updated: %v",
+				appInfoErr,
+			)
+		}
+	}
+
+	var outdated GamesConfig
+	for _, game := range b.gamesConfig {
+		var reason string
+		switch {
+		case len(game.Depots) > 0:
+			reason = b.pinnedGameUpdateReason(game)
+		case appInfoErr != nil:
+			reason = "the current build is unknown"
+		default:
+			reason = b.gameUpdateReason(game, appInfo[game.Id])
+			if info, ok := appInfo[game.Id]; ok && !dryRun {
+				b.db.BranchDlc[game.Name] = steamcmd.BranchDlc(info, game.BetaBranch)
+			}
+		}
+		if reason == "" {
+			log.Printf("%s is up-to-date", game.Name)
+			continue
+		}
+		log.Printf("%s will be updated: %s", game.Name, reason)
+		outdated = append(outdated, game)
+	}
+
+	return outdated, nil
+}
+
+// gameUpdateReason returns why the game needs to be updated, or an empty string if the installed
+// build is the current build of the branch.
+func (b *Boiler) gameUpdateReason(game GameConfig, appInfo vdf.Object) string {
+	if last, ok := b.lastInstalledBuild(game.Name); ok && last.Pinned {
+		return "pinned depots are installed"
+	}
+
+	manifest, err := steamcmd.ReadAppManifest(filepath.Join(b.config.GamesDir, game.Name), game.Id)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "not installed"
+	case err != nil:
+		return err.Error()
+	}
+
+	if !strings.EqualFold(branchName(manifest.BetaKey), branchName(game.BetaBranch)) {
+		return fmt.Sprintf(
+			"branch %s is installed instead of %s",
+			branchName(manifest.BetaKey),
+			branchName(game.BetaBranch),
+		)
+	}
+
+	current, ok := steamcmd.BranchBuildId(appInfo, game.BetaBranch)
+	if !ok {
+		return fmt.Sprintf("the current build of branch %s is unknown", branchName(game.BetaBranch))
+	}
+	if current != manifest.BuildId {
+		return fmt.Sprintf("build %s is installed, build %s is available", manifest.BuildId, current)
+	}
+
+	return ""
+}
+
+// pinnedGameUpdateReason returns why the pinned depots of the game need to be installed, or an
+// empty string if they are installed.
+func (b *Boiler) pinnedGameUpdateReason(game GameConfig) string {
+	last, ok := b.lastInstalledBuild(game.Name)
+	if !ok || !last.sameAs(pinnedBuild(game, last)) {
+		return "the pinned depots are not installed"
+	}
+	return ""
+}
+
+// branchName returns the name of the beta branch, or public if no beta branch is given.
+func branchName(betaBranch string) string {
+	if betaBranch == "" {
+		return steamcmd.PublicBranch
+	}
+	return betaBranch
+}
+
+// pinnedBuild returns the build that is installed by downloading the pinned depots of the game.
+// The build ID is taken from the last installed build if it has the same depots.
+func pinnedBuild(game GameConfig, last InstalledBuild) InstalledBuild {
+	build := InstalledBuild{
+		Depots: game.Depots,
+		Pinned: true,
+	}
+	if slices.Equal(last.Depots, game.Depots) {
+		build.BuildId = last.BuildId
+	}
+	return build
+}
+
+func toDepotManifests(depots []Depot) []steamcmd.DepotManifest {
+	var result []steamcmd.DepotManifest
+	for _, depot := range depots {
+		result = append(result, steamcmd.DepotManifest{
+			DepotId:    depot.DepotId,
+			ManifestId: depot.ManifestId,
+		})
+	}
+	return result
+}
+
+func (b *Boiler) lastInstalledBuild(gameName string) (InstalledBuild, bool) {
+	builds := b.db.InstalledBuilds[gameName]
+	if len(builds) == 0 {
+		return InstalledBuild{}, false
+	}
+	return builds[len(builds)-1], true
+}
+
+// recordInstalledBuilds adds the currently installed build of the games to their history, unless
+// it is already the last known build.
+func (b *Boiler) recordInstalledBuilds(games GamesConfig) error {
+	var resultErr error
+	for _, game := range games {
+		last, _ := b.lastInstalledBuild(game.Name)
+		var build InstalledBuild
+		if len(game.Depots) > 0 {
+			build = pinnedBuild(game, last)
+		} else {
+			manifest, err := steamcmd.ReadAppManifest(
+				filepath.Join(b.config.GamesDir, game.Name),
+				game.Id,
+			)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				continue
+			case err != nil:
+				resultErr = errors.Join(resultErr, err)
+				continue
+			}
+			build = InstalledBuild{BuildId: manifest.BuildId}
+			for _, depot := range manifest.Depots {
+				build.Depots = append(build.Depots, Depot{
+					DepotId:    depot.DepotId,
+					ManifestId: depot.ManifestId,
+				})
+			}
+		}
+
+		b.addInstalledBuild(game.Name, build)
+	}
+
+	return resultErr
+}
+
+func (b *Boiler) addInstalledBuild(gameName string, build InstalledBuild) {
+	if last, ok := b.lastInstalledBuild(gameName); ok && last.sameAs(build) {
+		return
+	}
+
+	build.InstalledAt = time.Now()
+	builds := append(b.db.InstalledBuilds[gameName], build)
+	if len(builds) > maxInstalledBuilds {
+		builds = builds[len(builds)-maxInstalledBuilds:]
+	}
+	b.db.InstalledBuilds[gameName] = builds
+}
+
+type RollbackOpts struct {
+	// If true, the user will be logged out after the rollback completes.
+	Logout bool
+}
+
+// Rollback reinstalls the build of the game that was installed before the current one, by
+// downloading the manifests of its depots. The game is pinned to these depots in the games
+// configuration so that updates do not undo the rollback. Remove the Depots of the game from the
+// games configuration to follow its branch again.
+func (b *Boiler) Rollback(ctx context.Context, gameName string, opts RollbackOpts) error {
+	gameIndex := -1
+	for i, game := range b.gamesConfig {
+		if game.Name == gameName {
+			gameIndex = i
+			break
+		}
+	}
+	if gameIndex == -1 {
+		return fmt.Errorf("game %s not found in the games configuration", gameName)
+	}
+	game := b.gamesConfig[gameIndex]
+
+	builds := b.db.InstalledBuilds[gameName]
+	if len(builds) < 2 {
+		return fmt.Errorf("no build of %s before the current one is known", gameName)
+	}
+	previous := builds[len(builds)-2]
+	if len(previous.Depots) == 0 {
+		return fmt.Errorf("the depots of the previous build of %s are unknown", gameName)
+	}
+
+	authenticator, err := b.authenticator()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rolling back %s to build %s", gameName, previous.BuildId)
+	err = steamcmd.Exec(ctx, steamcmd.Opts{
+		Authenticator:       authenticator,
+		ConfigPaths:         b.steamCmdConfigPaths(),
+		ConfirmationTimeout: b.config.LoginConfirmationTimeout,
+		DownloadGames: []steamcmd.DownloadGameOpts{{
+			Id:       game.Id,
+			Name:     game.Name,
+			Depots:   toDepotManifests(previous.Depots),
+			Platform: game.Platform,
+		}},
+		InactivityTimeout: b.config.SteamCmdInactivityTimeout,
+		InstallDir:        b.config.GamesDir,
+		IsolatedHome:      b.config.SteamCmdIsolatedHome,
+		LoginUsername:     b.config.LoginUsername,
+		Logout:            opts.Logout,
+		RunTimeout:        b.config.SteamCmdRunTimeout,
+		SteamCmdPath:      b.config.SteamCmdPath,
+	})
+	if err != nil {
+		return fmt.Errorf("error downloading the depots of build %s: %w", previous.BuildId, err)
+	}
+
+	// The current build is removed so that another rollback goes further back.
+	previous.Pinned = true
+	b.db.InstalledBuilds[gameName] = append(builds[:len(builds)-2], previous)
+	game.Depots = previous.Depots
+	b.gamesConfig[gameIndex] = game
+
+	log.Printf(
+		"%s is pinned to build %s. To update it again, remove its Depots from the games "+
+			"configuration.",
+		gameName,
+		previous.BuildId,
+	)
+
+	return b.Save()
+}
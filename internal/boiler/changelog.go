@@ -0,0 +1,110 @@
+package boiler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+)
+
+// WorkshopItemChangelog contains the changelog entries of a workshop item.
+type WorkshopItemChangelog struct {
+	WorkshopItemWithId
+	// The entries, newest first.
+	Entries []ChangelogEntry
+}
+
+// Changelog returns the changelog entries of the workshop items of the game, including their
+// dependencies, in dependency order. Workshop items without matching entries are omitted.
+// Only entries published after since are returned. If since is zero, the entries that were
+// retrieved by the last update in which workshop items were updated are returned, see
+// [Database.ChangelogsRetrieved].
+func (b *Boiler) Changelog(gameName string, since time.Time) ([]WorkshopItemChangelog, error) {
+	index := slices.IndexFunc(b.gamesConfig, func(game GameConfig) bool {
+		return game.Name == gameName
+	})
+	if index < 0 {
+		return nil, fmt.Errorf("game %s is not in the games configuration", gameName)
+	}
+
+	items, err := b.gamesConfig[index].GetWorkshopItemsOrdered(b.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WorkshopItemChangelog
+	for _, item := range items {
+		var entries []ChangelogEntry
+		for _, entry := range item.Changelog {
+			if since.IsZero() && entry.Retrieved.Equal(b.db.ChangelogsRetrieved) ||
+				!since.IsZero() && entry.Time.After(since) {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) > 0 {
+			result = append(result, WorkshopItemChangelog{
+				WorkshopItemWithId: item,
+				Entries:            entries,
+			})
+		}
+	}
+	return result, nil
+}
+
+// updateChangelogs retrieves the changelogs of the workshop items and adds the new entries to
+// the database. Failures are logged since the changelog is informational.
+func (b *Boiler) updateChangelogs(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	retrieved := time.Now()
+	b.db.ChangelogsRetrieved = retrieved
+	for _, id := range ids {
+		log.Printf("Getting changelog of workshop item %d", id)
+		entries, err := b.workshop.GetChangelog(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("WARNING: failed to get changelog of workshop item %d: %v", id, err)
+			continue
+		}
+
+		item, ok := b.db.WorkshopItems[id]
+		if !ok {
+			return fmt.Errorf("workshop item %d not in database on changelog update", id)
+		}
+		item.Changelog = mergeChangelog(item.Changelog, entries, retrieved)
+		b.db.WorkshopItems[id] = item
+	}
+	return nil
+}
+
+// mergeChangelog adds the entries that are not yet in changelog, marking them as retrieved at
+// the given time. The result is sorted newest first.
+func mergeChangelog(
+	changelog []ChangelogEntry,
+	entries []steamworkshop.ChangelogEntry,
+	retrieved time.Time,
+) []ChangelogEntry {
+	for _, entry := range entries {
+		if slices.ContainsFunc(changelog, func(existing ChangelogEntry) bool {
+			return existing.Time.Equal(entry.Time)
+		}) {
+			continue
+		}
+		changelog = append(changelog, ChangelogEntry{
+			Time:        entry.Time,
+			Description: entry.Description,
+			Retrieved:   retrieved,
+		})
+	}
+	slices.SortStableFunc(changelog, func(a, b ChangelogEntry) int {
+		return b.Time.Compare(a.Time)
+	})
+	return changelog
+}
@@ -1,13 +1,87 @@
 package boiler
 
+import "time"
+
 type Config struct {
 	// Points to the path containing the [Database] JSON config.
 	DatabasePath string
+	// The maximum combined size in bytes of the workshop items downloaded per steamcmd
+	// invocation. 0 is unlimited.
+	DownloadBatchMaxBytes uint64
+	// The maximum amount of workshop items downloaded per steamcmd invocation. Items that fail in
+	// one invocation do not affect the others. 0 is unlimited.
+	DownloadBatchMaxItems int
+	// The amount of steamcmd instances that download workshop items concurrently, each with its
+	// own isolated home directory. Items downloaded this way are downloaded in full instead of
+	// updated. 0 or 1 downloads sequentially.
+	DownloadParallelism int
+	// The amount of times workshop items that failed to download are retried.
+	DownloadRetries int
+	// The time to wait before the first retry, e.g. 30s. Doubles with every subsequent retry.
+	// Defaults to [DefaultDownloadRetryBackoff].
+	DownloadRetryBackoff time.Duration `json:",format:units"`
 	// Points to the path containing the [GamesConfig].
 	GamesConfPath string
 	// The path where games and workshop items will be downloaded.
 	GamesDir string
+	// Determines how the login prompts of steamcmd are answered. One of:
+	//   - "": steamcmd reads the answers from stdin.
+	//   - "terminal": boiler prompts for the answers.
+	//   - "file": the answers are read from LoginPasswordFile and LoginSteamGuardCodeFile.
+	//   - "env": the answers are read from the BOILER_STEAM_PASSWORD and
+	//     BOILER_STEAM_GUARD_CODE environment variables.
+	LoginAuthenticator string
+	// The time to wait for the login to be confirmed in the Steam Mobile app, e.g. 2m.
+	LoginConfirmationTimeout time.Duration `json:",format:units"`
+	// Path to a file containing the password of LoginUsername.
+	LoginPasswordFile string
+	// Path to a file containing the base64 encoded shared_secret of a Steam mobile
+	// authenticator. If set, Steam Guard codes are generated from it, allowing unattended logins.
+	LoginSharedSecretFile string
+	// Path to a file containing a Steam Guard code.
+	LoginSteamGuardCodeFile string
 	// Username used to log in with steamcmd.
 	LoginUsername string
-	SteamCmdPath  string
+	// The base URL of the Steam Web API. Defaults to https://api.steampowered.com.
+	SteamApiBaseUrl string
+	// Path to a file containing a Steam Web API key, see https://steamcommunity.com/dev/apikey.
+	// If set, the details and required items of workshop items are retrieved in batches using the
+	// Web API instead of scraping the page of every workshop item. The pages of new and updated
+	// workshop items are still retrieved for their required apps, which the Web API does not
+	// list. Required by the search command.
+	SteamApiKeyFile string
+	// Path to the config.vdf of steamcmd, checked for cached credentials after logging out.
+	// If empty, the common locations are checked.
+	SteamCmdConfigPath string
+	// If a steamcmd invocation does not produce output for this long, e.g. 10m, steamcmd is asked
+	// to quit and killed if it does not. 0 disables the timeout.
+	SteamCmdInactivityTimeout time.Duration `json:",format:units"`
+	// If true, steamcmd is run with a temporary home directory so that no credentials remain on
+	// the system. The home directory is shared by the steamcmd invocations of a command and
+	// destroyed when they are done, after which the config.vdf next to the steamcmd binary and
+	// SteamCmdConfigPath are verified to not contain a login.
+	SteamCmdIsolatedHome bool
+	SteamCmdPath         string
+	// The maximum duration of a steamcmd invocation, e.g. 6h. When exceeded, steamcmd is asked to
+	// quit and killed if it does not. 0 disables the timeout.
+	SteamCmdRunTimeout time.Duration `json:",format:units"`
+	// The base URL of the Steam Community website. Defaults to
+	// https://steamcommunity.com.
+	SteamCommunityBaseUrl string
+	// The URL of the proxy used for requests to Steam, e.g. http://localhost:3128. If empty, the
+	// proxy is taken from the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+	SteamHttpProxy string
+	// The maximum amount of requests per second sent to Steam. Defaults to 5. A negative value
+	// is unlimited.
+	SteamHttpRateLimit float64
+	// The amount of times a request to Steam that failed due to a network error, rate limiting,
+	// or a server error is retried. Defaults to 3. A negative value disables retries.
+	SteamHttpRetries int
+	// The time to wait before the first retry of a request to Steam, e.g. 2s. Doubles with every
+	// subsequent retry unless Steam asks to wait longer. Defaults to 1s.
+	SteamHttpRetryBackoff time.Duration `json:",format:units"`
+	// The maximum duration of a request to Steam, e.g. 30s. 0 is unlimited.
+	SteamHttpTimeout time.Duration `json:",format:units"`
+	// The User-Agent header sent with requests to Steam. If empty, Go's default is used.
+	SteamUserAgent string
 }
@@ -2,6 +2,7 @@ package boiler
 
 import (
 	"path"
+	"slices"
 	"strconv"
 	"time"
 
@@ -10,6 +11,15 @@ import (
 
 type Database struct {
 	Collections map[uint64]Collection
+	// The builds that have been installed for every game, keyed by the name of the game.
+	// The last build is the one that is currently installed.
+	InstalledBuilds map[string][]InstalledBuild `json:",omitempty"`
+	// The IDs of the DLC contained by the configured branch of every game, keyed by the name of
+	// the game. Retrieved along with the current build of the game.
+	BranchDlc map[string][]int `json:",omitempty"`
+	// Time when the changelogs of the workshop items were last retrieved, i.e. by the last update
+	// in which workshop items were updated. Zero if no changelog has been retrieved yet.
+	ChangelogsRetrieved time.Time `json:",omitzero"`
 	// Contains the original paths, relative to the content dir. Order is important.
 	PathChanges   []string
 	WorkshopItems map[uint64]WorkshopItem
@@ -28,6 +38,82 @@ type WorkshopItem struct {
 	// Time when the workshop item was last updated.
 	TimeUpdated time.Time
 	Title       string
+	// The size of the workshop item in bytes, 0 if unknown.
+	FileSize uint64 `json:",omitempty"`
+	// Whether the workshop item could be downloaded when its details were last retrieved.
+	// If not, the other details are the last known ones.
+	Availability Availability `json:",omitempty"`
+	// The reason given by Steam for banning the workshop item.
+	BanReason string `json:",omitempty"`
+	// The ID of the manifest of the content of the workshop item. Changes with every update of
+	// the content.
+	ContentFile uint64 `json:",string,omitempty"`
+	// The Steam ID of the author of the workshop item.
+	Creator uint64 `json:",string,omitempty"`
+	// The description of the workshop item in BBCode.
+	Description string `json:",omitempty"`
+	// The URL of the preview image of the workshop item.
+	PreviewUrl string `json:",omitempty"`
+	// The amount of users subscribed to the workshop item when its details were last retrieved.
+	Subscriptions int      `json:",omitempty"`
+	Tags          []string `json:",omitempty"`
+	// Who can see the workshop item.
+	Visibility steamworkshop.Visibility `json:",omitempty"`
+	// The apps, such as DLC, that the workshop item requires according to its workshop page.
+	RequiredApps []RequiredApp `json:",omitempty"`
+	// The updates of the workshop item, newest first. Only retrieved when the workshop item is
+	// updated, so older updates might be missing.
+	Changelog []ChangelogEntry `json:",omitempty"`
+}
+
+// ChangelogEntry is an update of a workshop item as listed on its changelog page.
+type ChangelogEntry struct {
+	// Time when the update was published.
+	Time        time.Time
+	Description string `json:",omitempty"`
+	// Time when the entry was retrieved.
+	Retrieved time.Time
+}
+
+// RequiredApp is an app, such as a DLC, that is required by a workshop item.
+type RequiredApp struct {
+	Id   int
+	Name string
+}
+
+// Availability describes whether a workshop item can be downloaded.
+type Availability string
+
+const (
+	AvailabilityAvailable Availability = ""
+	// The workshop item does not exist or has been removed.
+	AvailabilityRemoved Availability = "removed"
+	// The workshop item is private or otherwise hidden.
+	AvailabilityPrivate Availability = "private"
+	// The workshop item has been banned by Steam.
+	AvailabilityBanned Availability = "banned"
+	// Steam has not returned the details of the workshop item yet.
+	AvailabilityUnknown Availability = "unknown"
+)
+
+// availabilityOf returns the availability of the workshop item according to its details.
+// Returns false if the result does not indicate the availability, e.g. because Steam failed to
+// retrieve the details.
+func availabilityOf(detail steamworkshop.FileDetailApi) (Availability, bool) {
+	switch {
+	case detail.Result == steamworkshop.ResultFileNotFound:
+		return AvailabilityRemoved, true
+	case detail.Result == steamworkshop.ResultAccessDenied:
+		return AvailabilityPrivate, true
+	case detail.Result != steamworkshop.ResultOk:
+		return AvailabilityAvailable, false
+	case detail.Visibility == steamworkshop.VisibilityPrivate:
+		return AvailabilityPrivate, true
+	case detail.Banned:
+		return AvailabilityBanned, true
+	default:
+		return AvailabilityAvailable, true
+	}
 }
 
 type WorkshopItemWithId struct {
@@ -41,6 +127,30 @@ func (w WorkshopItemWithId) PathContentSuffix() string {
 	return path.Join(strconv.Itoa(w.CreatorAppId), strconv.FormatUint(w.Id, 10))
 }
 
+// Depot identifies a specific version of a depot of a game.
+type Depot struct {
+	DepotId    int
+	ManifestId uint64 `json:",string"`
+}
+
+type InstalledBuild struct {
+	// The build ID as reported by steamcmd. Empty if unknown.
+	BuildId string
+	Depots  []Depot
+	// True if the build was installed using pinned depots. In that case, the app manifest of
+	// steamcmd does not reflect the installed build.
+	Pinned bool
+	// Time when the build was first seen installed.
+	InstalledAt time.Time
+}
+
+// sameAs returns true if both builds have the same content.
+func (ib InstalledBuild) sameAs(other InstalledBuild) bool {
+	return ib.BuildId == other.BuildId &&
+		ib.Pinned == other.Pinned &&
+		slices.Equal(ib.Depots, other.Depots)
+}
+
 type Collection struct {
 	Items []CollectionItem
 }
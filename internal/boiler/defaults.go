@@ -1,5 +1,8 @@
 package boiler
 
+import "time"
+
 const ConfigFilePath = "/etc/boiler/boiler.json"
 const SteamWorkshopSubDir = ".workshop"
 const SteamWorkshopItemPrefix = SteamWorkshopSubDir + "/steamapps/workshop/content"
+const DefaultDownloadRetryBackoff = 30 * time.Second
@@ -0,0 +1,77 @@
+package boiler
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+)
+
+// printPlan prints what Download would do with the steamcmd options.
+func (b *Boiler) printPlan(
+	downOpts steamcmd.Opts,
+	filenameCasingUpdates []WorkshopItemWithId,
+) error {
+	scripts, err := steamcmd.Scripts(downOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, game := range downOpts.DownloadGames {
+		branch := branchName(game.BetaBranch)
+		switch {
+		case len(game.Depots) > 0:
+			log.Printf("Would download the pinned depots of %s (%d)", game.Name, game.Id)
+		case game.Validate:
+			log.Printf("Would update and validate %s (%d), branch %s", game.Name, game.Id, branch)
+		default:
+			log.Printf("Would update %s (%d), branch %s", game.Name, game.Id, branch)
+		}
+	}
+
+	downloading := make(map[uint64]struct{}, len(downOpts.DownloadWorkshopItems))
+	for _, item := range downOpts.DownloadWorkshopItems {
+		downloading[item.WorkshopItemId] = struct{}{}
+		log.Printf(
+			"Would download workshop item %d (%s)",
+			item.WorkshopItemId,
+			b.db.WorkshopItems[item.WorkshopItemId].Title,
+		)
+	}
+
+	basePath := filepath.Join(b.config.GamesDir, SteamWorkshopItemPrefix)
+	for _, p := range b.pathChangesOf(filenameCasingUpdates) {
+		log.Printf("Would restore the casing of %s", filepath.Join(basePath, p))
+	}
+
+	for i, script := range scripts {
+		log.Printf("Would run steamcmd script %d of %d:\n%s", i+1, len(scripts), script)
+	}
+	if downOpts.Logout && downOpts.LoginUsername != "" && !downOpts.IsolatedHome {
+		log.Printf("Would log out %s", downOpts.LoginUsername)
+	}
+
+	for _, item := range filenameCasingUpdates {
+		log.Printf(
+			"Would make the files of workshop item %d (%s) lowercase",
+			item.Id,
+			item.Title,
+		)
+	}
+
+	links, err := b.symlinks(downloading)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		log.Printf("Would link %s to %s", link.name, link.target)
+	}
+
+	for _, game := range b.gamesConfig {
+		if game.PostInstall != "" {
+			log.Printf("Would run postinstall %s", game.PostInstall)
+		}
+	}
+
+	return nil
+}
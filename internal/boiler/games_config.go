@@ -3,6 +3,8 @@ package boiler
 import (
 	"fmt"
 	"slices"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
 )
 
 type GamesConfig []GameConfig
@@ -18,6 +20,47 @@ type GameConfig struct {
 	WorkshopDependencyAdd      map[uint64][]IdWithComment
 	WorkshopDependencyRemove   map[uint64][]IdWithComment
 	WorkshopCollections        []IdWithComment
+	// If set, these depot manifests are installed instead of the latest build of BetaBranch.
+	// Set by rollback.
+	Depots []Depot `json:",omitempty"`
+	// Path to a file containing the password of BetaBranch, if it is private.
+	BetaPasswordFile string `json:",omitempty"`
+	// If set, the game is downloaded for this platform instead of the current one.
+	// One of windows, linux, or macos.
+	Platform string `json:",omitempty"`
+}
+
+// validate returns an error if the configuration of a game is invalid.
+func (config GamesConfig) validate() error {
+	for _, game := range config {
+		switch game.Platform {
+		case "", steamcmd.PlatformWindows, steamcmd.PlatformLinux, steamcmd.PlatformMacOS:
+		default:
+			return fmt.Errorf("unknown Platform %s of game %s", game.Platform, game.Name)
+		}
+		if game.BetaPasswordFile != "" && game.BetaBranch == "" {
+			return fmt.Errorf("game %s has a BetaPasswordFile but no BetaBranch", game.Name)
+		}
+	}
+	return nil
+}
+
+// betaPassword returns the content of BetaPasswordFile, or an empty string if it is not set.
+func (gc GameConfig) betaPassword() (string, error) {
+	if gc.BetaPasswordFile == "" {
+		return "", nil
+	}
+	password, err := steamcmd.ReadSecretFile(gc.BetaPasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading BetaPasswordFile of %s: %w", gc.Name, err)
+	}
+	return password, nil
+}
+
+// ProvidesApp returns true if the app is the game, its workshop app, or one of branchDlc, the DLC
+// contained by BetaBranch.
+func (gc GameConfig) ProvidesApp(appId int, branchDlc []int) bool {
+	return appId == gc.Id || appId == gc.WorkshopAppId || slices.Contains(branchDlc, appId)
 }
 
 func (config GamesConfig) UpdateComments(db *Database) {
@@ -164,3 +164,18 @@ func TestGameConfig_GetWorkshopItemsOrdered_WithCollections(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedIds, actualIds)
 }
+
+func TestGameConfig_ProvidesApp(t *testing.T) {
+	game := boiler.GameConfig{
+		Name:          "Arma3",
+		Id:            233780,
+		WorkshopAppId: 107410,
+		BetaBranch:    "creatordlc",
+	}
+	branchDlc := []int{1681170}
+	assert.True(t, game.ProvidesApp(233780, branchDlc))
+	assert.True(t, game.ProvidesApp(107410, branchDlc))
+	assert.True(t, game.ProvidesApp(1681170, branchDlc))
+	assert.False(t, game.ProvidesApp(304380, branchDlc))
+	assert.False(t, game.ProvidesApp(1681170, nil))
+}
@@ -0,0 +1,730 @@
+//go:build unix
+
+package boiler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/internal/boiler"
+	"github.com/MatthiasKunnen/boiler/internal/steamfake"
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+	"github.com/go-json-experiment/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	steamfake.RunIfSteamcmd()
+	os.Exit(m.Run())
+}
+
+// integration is a boiler setup that uses a fake steamcmd and a fake Steam server.
+type integration struct {
+	config   boiler.Config
+	server   *steamfake.Server
+	steamcmd *steamfake.Steamcmd
+}
+
+func newIntegration(t *testing.T, gamesConf string) *integration {
+	dir := t.TempDir()
+	gamesDir := filepath.Join(dir, "games")
+	gamesConfPath := filepath.Join(dir, "games.json")
+	if err := os.Mkdir(gamesDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gamesConfPath, []byte(gamesConf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := steamfake.NewSteamcmd(t)
+	fake.SetApp(233780, steamfake.App{
+		Name:     "Arma 3",
+		Branches: map[string]string{steamcmd.PublicBranch: "100"},
+		Files:    map[string]string{"arma3": "binary"},
+	})
+
+	server := steamfake.NewServer(t)
+	return &integration{
+		config: boiler.Config{
+			DatabasePath:          filepath.Join(dir, "db.json"),
+			DownloadRetryBackoff:  time.Millisecond,
+			GamesConfPath:         gamesConfPath,
+			GamesDir:              gamesDir,
+			SteamApiBaseUrl:       server.URL,
+			SteamCmdPath:          fake.Path,
+			SteamCommunityBaseUrl: server.URL,
+			SteamHttpRateLimit:    -1,
+		},
+		server:   server,
+		steamcmd: fake,
+	}
+}
+
+// update updates the database and downloads what is out of date, like the update command.
+func (i *integration) update(t *testing.T, opts boiler.DownloadOpts) error {
+	b, err := boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.UpdateDatabase(context.Background(), boiler.UpdateOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Download(context.Background(), opts)
+}
+
+func (i *integration) database(t *testing.T) boiler.Database {
+	dbJson, err := os.ReadFile(i.config.DatabasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var db boiler.Database
+	if err := json.Unmarshal(dbJson, &db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func (i *integration) workshopContentDir(id string) string {
+	return filepath.Join(i.config.GamesDir, boiler.SteamWorkshopItemPrefix, "107410", id)
+}
+
+// scriptsWith returns the scripts, executed since skip scripts, containing the command.
+func (i *integration) scriptsWith(skip int, command string) []string {
+	var result []string
+	for _, script := range i.steamcmd.Scripts()[skip:] {
+		if strings.Contains(script, command) {
+			result = append(result, script)
+		}
+	}
+	return result
+}
+
+func TestIntegration_UpdateCycle(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"MakeWorkshopItemsLowercase": true,
+		"WorkshopItems": [["2", ""]],
+		"WorkshopCollections": [["10", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+		FileSize: 4,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+		FileSize: 8, Requires: []uint64{1}, Creator: 76561198194647182,
+		Description: "[h1]ACE3[/h1]", Subscriptions: 42, Tags: []string{"Mod", "x64"},
+	})
+	i.server.SetCollection(steamfake.Collection{Id: 10, Items: []uint64{2}})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{
+		Files: map[string]string{"Addons/CBA_Main.PBO": "cba"},
+	})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{
+		Files: map[string]string{"Addons/ACE_Main.PBO": "ace", "Mod.CPP": "name"},
+	})
+
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	db := i.database(t)
+	assert.Equal(t, "CBA", db.WorkshopItems[1].Title)
+	assert.Equal(t, "ACE", db.WorkshopItems[2].Title)
+	assert.Equal(t, []uint64{1}, db.WorkshopItems[2].Requires)
+	assert.Equal(t, uint64(8), db.WorkshopItems[2].FileSize)
+	assert.Equal(t, uint64(76561198194647182), db.WorkshopItems[2].Creator)
+	assert.Equal(t, "[h1]ACE3[/h1]", db.WorkshopItems[2].Description)
+	assert.Equal(t, 42, db.WorkshopItems[2].Subscriptions)
+	assert.Equal(t, []string{"Mod", "x64"}, db.WorkshopItems[2].Tags)
+	assert.Equal(t, uint64(created.Unix()), db.WorkshopItems[2].ContentFile)
+	assert.Equal(t, i.server.URL+"/preview/2.jpg", db.WorkshopItems[2].PreviewUrl)
+	assert.Equal(t, []boiler.CollectionItem{{Id: 2}}, db.Collections[10].Items)
+	assert.False(t, db.WorkshopItems[1].LastDownloaded.IsZero())
+	assert.False(t, db.WorkshopItems[2].LastDownloaded.IsZero())
+	assert.ElementsMatch(t, []string{
+		"107410/1/Addons",
+		"107410/1/Addons/CBA_Main.PBO",
+		"107410/2/Addons",
+		"107410/2/Addons/ACE_Main.PBO",
+		"107410/2/Mod.CPP",
+	}, db.PathChanges)
+	assert.Equal(t, "100", db.InstalledBuilds["Arma3"][0].BuildId)
+
+	assert.FileExists(t, filepath.Join(i.workshopContentDir("1"), "addons/cba_main.pbo"))
+	assert.FileExists(t, filepath.Join(i.workshopContentDir("2"), "addons/ace_main.pbo"))
+	assert.FileExists(t, filepath.Join(i.workshopContentDir("2"), "mod.cpp"))
+	assert.FileExists(t, filepath.Join(i.config.GamesDir, "Arma3/arma3"))
+	link, err := os.Readlink(filepath.Join(i.config.GamesDir, "Arma3/mods"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(i.config.GamesDir, boiler.SteamWorkshopItemPrefix, "107410"), link)
+
+	// Nothing changed, nothing is downloaded.
+	scripts := len(i.steamcmd.Scripts())
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	assert.Empty(t, i.scriptsWith(scripts, "app_update"))
+	assert.Empty(t, i.scriptsWith(scripts, "workshop_download_item"))
+
+	// An updated workshop item is downloaded again.
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created,
+		TimeUpdated: time.Now().Add(time.Minute), FileSize: 8, Requires: []uint64{1},
+	})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{
+		Files: map[string]string{"Addons/ACE_Main.PBO": "ace v2", "Mod.CPP": "name"},
+	})
+	scripts = len(i.steamcmd.Scripts())
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	downloads := i.scriptsWith(scripts, "workshop_download_item")
+	if assert.Len(t, downloads, 1) {
+		assert.Contains(t, downloads[0], "workshop_download_item 107410 2")
+		assert.NotContains(t, downloads[0], "workshop_download_item 107410 1")
+	}
+
+	content, err := os.ReadFile(filepath.Join(i.workshopContentDir("2"), "addons/ace_main.pbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ace v2", string(content))
+	entries, err := os.ReadDir(filepath.Join(i.workshopContentDir("2"), "addons"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "the previous casing of the files remains")
+	assert.Len(t, i.database(t).PathChanges, 5)
+}
+
+func TestIntegration_DryRunChecksBuilds(t *testing.T) {
+	i := newIntegration(t, `[{"Name": "Arma3", "Id": 233780}]`)
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	scripts := len(i.steamcmd.Scripts())
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{DryRun: true}))
+	assert.Contains(t, logs.String(), "Arma3 is up-to-date")
+	assert.NotContains(t, logs.String(), "Would update Arma3")
+
+	i.steamcmd.SetApp(233780, steamfake.App{
+		Name:     "Arma 3",
+		Branches: map[string]string{steamcmd.PublicBranch: "101"},
+		Files:    map[string]string{"arma3": "binary"},
+	})
+	logs.Reset()
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{DryRun: true}))
+	assert.Contains(
+		t,
+		logs.String(),
+		"Arma3 will be updated: build 100 is installed, build 101 is available",
+	)
+	assert.Contains(t, logs.String(), "Would update Arma3")
+	assert.Empty(t, i.scriptsWith(scripts, "app_update"))
+	assert.Equal(t, "100", i.database(t).InstalledBuilds["Arma3"][0].BuildId)
+}
+
+func TestIntegration_FailedWorkshopItem(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""], ["2", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+	})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{
+		Files: map[string]string{"mod.cpp": "cba"},
+	})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{FailReason: "Timeout"})
+
+	retries := 2
+	err := i.update(t, boiler.DownloadOpts{Retries: &retries})
+
+	var wsErr *steamcmd.WorkshopDownloadError
+	if assert.True(t, errors.As(err, &wsErr), "expected a WorkshopDownloadError, got %v", err) {
+		if assert.Len(t, wsErr.Failures, 1) {
+			assert.Equal(t, uint64(2), wsErr.Failures[0].WorkshopItemId)
+		}
+	}
+	assert.Len(t, i.scriptsWith(0, "workshop_download_item 107410 2"), 3)
+	db := i.database(t)
+	assert.False(t, db.WorkshopItems[1].LastDownloaded.IsZero())
+	assert.True(t, db.WorkshopItems[2].LastDownloaded.IsZero())
+	assert.FileExists(t, filepath.Join(i.workshopContentDir("1"), "mod.cpp"))
+}
+
+func TestIntegration_ZeroRetriesOverridesConfig(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""]]
+	}]`)
+	i.config.DownloadRetries = 2
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{FailReason: "Timeout"})
+
+	retries := 0
+	err := i.update(t, boiler.DownloadOpts{Retries: &retries})
+
+	var wsErr *steamcmd.WorkshopDownloadError
+	assert.True(t, errors.As(err, &wsErr), "expected a WorkshopDownloadError, got %v", err)
+	assert.Len(t, i.scriptsWith(0, "workshop_download_item 107410 1"), 1)
+}
+
+func TestIntegration_RemovedWorkshopItem(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""], ["2", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+	})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{})
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	i.server.RemoveWorkshopItem(1)
+	scripts := len(i.steamcmd.Scripts())
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{DownloadUpToDate: true}))
+	downloads := i.scriptsWith(scripts, "workshop_download_item")
+	if assert.Len(t, downloads, 1) {
+		assert.NotContains(t, downloads[0], "workshop_download_item 107410 1")
+	}
+	db := i.database(t)
+	assert.Equal(t, boiler.AvailabilityRemoved, db.WorkshopItems[1].Availability)
+	assert.Equal(t, "CBA", db.WorkshopItems[1].Title, "the last known title is lost")
+	assert.True(t, created.Equal(db.WorkshopItems[1].TimeUpdated))
+	assert.Equal(t, boiler.AvailabilityAvailable, db.WorkshopItems[2].Availability)
+
+	b, err := boiler.FromConfig(i.config)
+	assert.NoError(t, err)
+	err = b.UpdateDatabase(context.Background(), boiler.UpdateOpts{FailOnUnavailable: true})
+	assert.ErrorContains(t, err, "1 workshop item(s) are unavailable: [1]")
+}
+
+func TestIntegration_WorkshopItemResults(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""], ["2", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	cba := steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	}
+	ace := steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+	}
+	i.server.SetWorkshopItem(cba)
+	i.server.SetWorkshopItem(ace)
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{})
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	// 2 is the generic failure result, 15 is access denied.
+	cba.Result = 2
+	ace.Result = 15
+	i.server.SetWorkshopItem(cba)
+	i.server.SetWorkshopItem(ace)
+	b, err := boiler.FromConfig(i.config)
+	assert.NoError(t, err)
+	assert.NoError(t, b.UpdateDatabase(context.Background(), boiler.UpdateOpts{}))
+	db := i.database(t)
+	assert.Equal(t, boiler.AvailabilityAvailable, db.WorkshopItems[1].Availability)
+	assert.Equal(t, "CBA", db.WorkshopItems[1].Title)
+	assert.Equal(t, boiler.AvailabilityPrivate, db.WorkshopItems[2].Availability)
+
+	// The last known availability is kept, not reset.
+	cba.Result = 0
+	i.server.SetWorkshopItem(cba)
+	ace.Result = 2
+	i.server.SetWorkshopItem(ace)
+	b, err = boiler.FromConfig(i.config)
+	assert.NoError(t, err)
+	assert.NoError(t, b.UpdateDatabase(context.Background(), boiler.UpdateOpts{}))
+	db = i.database(t)
+	assert.Equal(t, boiler.AvailabilityAvailable, db.WorkshopItems[1].Availability)
+	assert.Equal(t, boiler.AvailabilityPrivate, db.WorkshopItems[2].Availability)
+}
+
+func TestIntegration_UnexpectedResultForNewWorkshopItem(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""], ["2", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{Id: 2, Result: 2})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{})
+
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	db := i.database(t)
+	assert.Equal(t, boiler.AvailabilityAvailable, db.WorkshopItems[1].Availability)
+	assert.Equal(t, boiler.AvailabilityUnknown, db.WorkshopItems[2].Availability)
+	assert.NotEmpty(t, i.scriptsWith(0, "workshop_download_item 107410 1"))
+	assert.Empty(t, i.scriptsWith(0, "workshop_download_item 107410 2"))
+	assert.Empty(t, i.scriptsWith(0, "workshop_download_item 0 2"))
+
+	b, err := boiler.FromConfig(i.config)
+	assert.NoError(t, err)
+	err = b.UpdateDatabase(context.Background(), boiler.UpdateOpts{FailOnUnavailable: true})
+	assert.ErrorContains(t, err, "1 workshop item(s) are unavailable: [2]")
+
+	// Once Steam returns the details, the item is downloaded.
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+	})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{})
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	assert.Equal(t, boiler.AvailabilityAvailable, i.database(t).WorkshopItems[2].Availability)
+	assert.NotEmpty(t, i.scriptsWith(0, "workshop_download_item 107410 2"))
+}
+
+func TestIntegration_RequiredApps(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"BetaBranch": "creatordlc",
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""]]
+	}]`)
+	i.steamcmd.SetApp(233780, steamfake.App{
+		Name: "Arma 3",
+		Branches: map[string]string{
+			steamcmd.PublicBranch: "100",
+			"creatordlc":          "101",
+		},
+		Dlc: map[int][]string{1681170: {"creatordlc"}},
+	})
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "WS compat", TimeCreated: created, TimeUpdated: created,
+		RequiresApps: []steamfake.StoreApp{
+			{Id: 1681170, Name: "Arma 3 Creator DLC: Western Sahara"},
+			{Id: 304380, Name: "Arma 3 Helicopters"},
+		},
+	})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{})
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// The DLC of the branch are retrieved along with the current build of the game.
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	assert.Contains(t, logs.String(), "The DLC of branch creatordlc of Arma3 are not known yet")
+	assert.NotContains(t, logs.String(), "requires app")
+
+	db := i.database(t)
+	assert.Equal(t, []boiler.RequiredApp{
+		{Id: 1681170, Name: "Arma 3 Creator DLC: Western Sahara"},
+		{Id: 304380, Name: "Arma 3 Helicopters"},
+	}, db.WorkshopItems[1].RequiredApps)
+	assert.Equal(t, map[string][]int{"Arma3": {1681170}}, db.BranchDlc)
+
+	logs.Reset()
+	b, err := boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, b.UpdateDatabase(context.Background(), boiler.UpdateOpts{}))
+	assert.Contains(
+		t,
+		logs.String(),
+		"requires app 304380 (Arma 3 Helicopters), which is not contained by branch creatordlc",
+	)
+	assert.NotContains(t, logs.String(), "requires app 1681170")
+	assert.NotContains(t, logs.String(), "not known yet")
+}
+
+func TestIntegration_RequiredApps_ApiKey(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""]]
+	}]`)
+	i.config.SteamApiKeyFile = filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(i.config.SteamApiKeyFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Unix(1700000000, 0)
+	item := steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "WS compat", TimeCreated: created, TimeUpdated: created,
+		Requires: []uint64{2},
+		RequiresApps: []steamfake.StoreApp{
+			{Id: 304380, Name: "Arma 3 Helicopters"},
+		},
+	}
+	i.server.SetWorkshopItem(item)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+	})
+
+	update := func() {
+		b, err := boiler.FromConfig(i.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, b.UpdateDatabase(context.Background(), boiler.UpdateOpts{}))
+	}
+	pageRequests := func(skip int) []string {
+		var result []string
+		for _, request := range i.server.Requests()[skip:] {
+			if strings.HasPrefix(request, "GET /sharedfiles/filedetails/?") {
+				result = append(result, request)
+			}
+		}
+		return result
+	}
+
+	update()
+	db := i.database(t)
+	assert.Equal(t, []uint64{2}, db.WorkshopItems[1].Requires)
+	assert.Equal(t, []boiler.RequiredApp{
+		{Id: 304380, Name: "Arma 3 Helicopters"},
+	}, db.WorkshopItems[1].RequiredApps)
+
+	requests := len(i.server.Requests())
+	update()
+	assert.Empty(t, pageRequests(requests), "pages of unchanged items are not retrieved")
+	assert.Len(t, i.database(t).WorkshopItems[1].RequiredApps, 1)
+
+	item.TimeUpdated = time.Unix(1700100000, 0)
+	item.RequiresApps = nil
+	i.server.SetWorkshopItem(item)
+	requests = len(i.server.Requests())
+	update()
+	assert.Equal(t, []string{"GET /sharedfiles/filedetails/?id=1"}, pageRequests(requests))
+	assert.Empty(t, i.database(t).WorkshopItems[1].RequiredApps)
+}
+
+func TestIntegration_Changelog(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", ""], ["2", ""]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	updated := time.Unix(1700100000, 0)
+	cba := steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA", TimeCreated: created, TimeUpdated: created,
+		Changelog: []steamfake.ChangeNote{{Time: created, Description: "Initial release"}},
+	}
+	i.server.SetWorkshopItem(cba)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: created,
+	})
+	i.steamcmd.SetWorkshopItem(1, steamfake.WorkshopContent{})
+	i.steamcmd.SetWorkshopItem(2, steamfake.WorkshopContent{})
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	assert.Empty(t, i.database(t).WorkshopItems[1].Changelog, "new items have no changelog")
+
+	cba.TimeUpdated = updated
+	cba.Changelog = append(cba.Changelog, steamfake.ChangeNote{
+		Time:        updated,
+		Description: "Fixed <things> & stuff",
+	})
+	i.server.SetWorkshopItem(cba)
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	changelog := i.database(t).WorkshopItems[1].Changelog
+	if assert.Len(t, changelog, 2) {
+		assert.True(t, updated.Equal(changelog[0].Time))
+		assert.Equal(t, "Fixed <things> & stuff", changelog[0].Description)
+		assert.True(t, created.Equal(changelog[1].Time))
+	}
+	assert.Empty(t, i.database(t).WorkshopItems[2].Changelog)
+
+	b, err := boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	justUpdated, err := b.Changelog("Arma3", time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, justUpdated, 1) {
+		assert.Equal(t, uint64(1), justUpdated[0].Id)
+		assert.Len(t, justUpdated[0].Entries, 2)
+	}
+	since, err := b.Changelog("Arma3", created)
+	assert.NoError(t, err)
+	if assert.Len(t, since, 1) {
+		assert.Len(t, since[0].Entries, 1)
+	}
+
+	// An update in which nothing changed does not affect which entries are new.
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	b, err = boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	justUpdated, err = b.Changelog("Arma3", time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, justUpdated, 1) {
+		assert.Equal(t, uint64(1), justUpdated[0].Id)
+		assert.Len(t, justUpdated[0].Entries, 2)
+	}
+
+	// Entries of items that were not updated by the last update are no longer new.
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ACE", TimeCreated: created, TimeUpdated: updated,
+		Changelog: []steamfake.ChangeNote{{Time: updated, Description: "ACE3"}},
+	})
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+	b, err = boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	justUpdated, err = b.Changelog("Arma3", time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, justUpdated, 1) {
+		assert.Equal(t, uint64(2), justUpdated[0].Id)
+		assert.Len(t, justUpdated[0].Entries, 1)
+	}
+	assert.Len(t, i.database(t).WorkshopItems[1].Changelog, 2, "the changelog is kept")
+
+	_, err = b.Changelog("Arma2", time.Time{})
+	assert.ErrorContains(t, err, "game Arma2 is not in the games configuration")
+}
+
+func TestIntegration_ApiKeyRetrievesRequirementsInBatches(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["3", ""]]
+	}]`)
+	i.config.SteamApiKeyFile = filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(i.config.SteamApiKeyFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Unix(1700000000, 0)
+	for id, requires := range map[uint64][]uint64{1: nil, 2: {1}, 3: {2, 1}} {
+		i.server.SetWorkshopItem(steamfake.WorkshopItem{
+			Id: id, AppId: 107410, Title: strconv.FormatUint(id, 10), TimeCreated: created,
+			TimeUpdated: created, Requires: requires,
+		})
+		i.steamcmd.SetWorkshopItem(id, steamfake.WorkshopContent{})
+	}
+
+	assert.NoError(t, i.update(t, boiler.DownloadOpts{}))
+
+	db := i.database(t)
+	assert.Equal(t, []uint64{2, 1}, db.WorkshopItems[3].Requires)
+	assert.Equal(t, []uint64{1}, db.WorkshopItems[2].Requires)
+	assert.Empty(t, db.WorkshopItems[1].Requires)
+	for _, item := range db.WorkshopItems {
+		assert.False(t, item.LastDownloaded.IsZero())
+	}
+	for _, request := range i.server.Requests() {
+		// The pages of new items are only retrieved for their required apps.
+		if strings.HasPrefix(request, "GET /sharedfiles/filedetails/?") {
+			continue
+		}
+		assert.Contains(t, request, "/IPublishedFileService/GetDetails/v1/?")
+		assert.Contains(t, request, "key=secret")
+	}
+}
+
+func TestIntegration_SearchAndAddWorkshopItem(t *testing.T) {
+	i := newIntegration(t, `[{
+		"Name": "Arma3",
+		"Id": 233780,
+		"WorkshopAppId": 107410,
+		"WorkshopItems": [["1", "CBA"]]
+	}]`)
+	created := time.Unix(1700000000, 0)
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 1, AppId: 107410, Title: "CBA_A3", TimeCreated: created, TimeUpdated: created,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 2, AppId: 107410, Title: "ace", TimeCreated: created, TimeUpdated: created,
+		Requires: []uint64{1, 3},
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 3, AppId: 107410, Title: "ACE Compat", TimeCreated: created, TimeUpdated: created,
+	})
+	i.server.SetWorkshopItem(steamfake.WorkshopItem{
+		Id: 4, AppId: 221100, Title: "ACE for another game", TimeCreated: created,
+		TimeUpdated: created,
+	})
+
+	b, err := boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.SearchWorkshop(context.Background(), "Arma3", "ace", 10)
+	assert.ErrorIs(t, err, steamworkshop.ErrNoApiKey)
+
+	i.config.SteamApiKeyFile = filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(i.config.SteamApiKeyFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	b, err = boiler.FromConfig(i.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.SearchWorkshop(context.Background(), "Arma3", "ace", 10)
+	assert.NoError(t, err)
+	var ids []uint64
+	for _, result := range results {
+		ids = append(ids, result.Id)
+	}
+	assert.Equal(t, []uint64{2, 3}, ids)
+
+	_, err = b.SearchWorkshop(context.Background(), "Arma2", "ace", 10)
+	assert.ErrorContains(t, err, "game Arma2 is not in the games configuration")
+
+	assert.NoError(t, b.AddWorkshopItem(context.Background(), "Arma3", 2))
+	gamesJson, err := os.ReadFile(i.config.GamesConfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var games boiler.GamesConfig
+	if err := json.Unmarshal(gamesJson, &games); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []boiler.IdWithComment{{1, "CBA_A3"}, {2, "ace"}}, games[0].WorkshopItems)
+	db := i.database(t)
+	assert.Equal(t, []uint64{1, 3}, db.WorkshopItems[2].Requires)
+	assert.Equal(t, "ACE Compat", db.WorkshopItems[3].Title)
+
+	assert.NoError(t, b.AddWorkshopItem(context.Background(), "Arma3", 2))
+	gamesJson, err = os.ReadFile(i.config.GamesConfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	games = nil
+	if err := json.Unmarshal(gamesJson, &games); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, games[0].WorkshopItems, 2, "adding an item twice must not duplicate it")
+}
@@ -0,0 +1,73 @@
+package boiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+)
+
+// SearchWorkshop searches the workshop of the game for workshop items matching the query.
+// Returns at most limit results, most relevant first. Requires SteamApiKeyFile to be set.
+func (b *Boiler) SearchWorkshop(
+	ctx context.Context,
+	gameName string,
+	query string,
+	limit int,
+) ([]steamworkshop.PublishedFileDetailApi, error) {
+	game, ok := b.gameConfig(gameName)
+	if !ok {
+		return nil, fmt.Errorf("game %s is not in the games configuration", gameName)
+	}
+
+	result, err := b.workshop.QueryFilesApi(ctx, steamworkshop.QueryFilesOpts{
+		AppId:      game.WorkshopAppId,
+		SearchText: query,
+		NumPerPage: limit,
+	})
+	if errors.Is(err, steamworkshop.ErrNoApiKey) {
+		return nil, fmt.Errorf("searching the workshop requires SteamApiKeyFile: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// AddWorkshopItem adds the workshop item to the WorkshopItems of the game and updates the
+// database, so that the details and dependencies of the workshop item are known.
+// The games configuration is saved as part of the update.
+func (b *Boiler) AddWorkshopItem(ctx context.Context, gameName string, id uint64) error {
+	index := slices.IndexFunc(b.gamesConfig, func(game GameConfig) bool {
+		return game.Name == gameName
+	})
+	if index < 0 {
+		return fmt.Errorf("game %s is not in the games configuration", gameName)
+	}
+
+	game := b.gamesConfig[index]
+	if slices.ContainsFunc(game.WorkshopItems, func(item IdWithComment) bool {
+		return item.Id == id
+	}) {
+		log.Printf("Workshop item %d is already in the WorkshopItems of %s", id, gameName)
+	} else {
+		game.WorkshopItems = append(game.WorkshopItems, IdWithComment{Id: id})
+		b.gamesConfig[index] = game
+		log.Printf("Added workshop item %d to %s", id, gameName)
+	}
+
+	return b.UpdateDatabase(ctx, UpdateOpts{})
+}
+
+// gameConfig returns the configuration of the game with the given name.
+func (b *Boiler) gameConfig(gameName string) (GameConfig, bool) {
+	for _, game := range b.gamesConfig {
+		if game.Name == gameName {
+			return game, true
+		}
+	}
+	return GameConfig{}, false
+}
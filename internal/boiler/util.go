@@ -2,6 +2,8 @@ package boiler
 
 import (
 	"errors"
+	"fmt"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 )
@@ -9,16 +11,20 @@ import (
 // overwriteSymlink creates a symlink and overwrites an existing file if it exists.
 // Atomicity depends on the system, see [os.Rename].
 func overwriteSymlink(target string, linkName string) error {
+	// The temporary symlink is created next to linkName so that it can be renamed to it.
 	var tempPath string
-	for i := 0; i < 5; i++ {
-		tempPath = filepath.Join(os.TempDir(), tempPath)
+	for i := 0; ; i++ {
+		tempPath = filepath.Join(
+			filepath.Dir(linkName),
+			fmt.Sprintf(".%s.%d", filepath.Base(linkName), rand.Uint32()),
+		)
 
 		err := os.Symlink(
 			target,
 			tempPath,
 		)
 		switch {
-		case errors.Is(err, os.ErrExist):
+		case errors.Is(err, os.ErrExist) && i < 5:
 			continue
 		case err != nil:
 			return err
@@ -26,5 +32,10 @@ func overwriteSymlink(target string, linkName string) error {
 		break
 	}
 
-	return os.Rename(tempPath, linkName)
+	err := os.Rename(tempPath, linkName)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
 }
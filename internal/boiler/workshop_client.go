@@ -0,0 +1,60 @@
+package boiler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+)
+
+// workshopClient returns the client used to retrieve the details of workshop items and
+// collections, configured using the Steam* HTTP settings of the config.
+func (config Config) workshopClient() (*steamworkshop.Client, error) {
+	opts := []steamworkshop.ClientOpt{
+		steamworkshop.WithTimeout(config.SteamHttpTimeout),
+		steamworkshop.WithUserAgent(config.SteamUserAgent),
+	}
+	if config.SteamHttpRateLimit != 0 {
+		opts = append(opts, steamworkshop.WithRateLimit(
+			config.SteamHttpRateLimit,
+			int(max(config.SteamHttpRateLimit, 1)),
+		))
+	}
+	if config.SteamHttpRetries != 0 || config.SteamHttpRetryBackoff != 0 {
+		retries := config.SteamHttpRetries
+		if retries == 0 {
+			retries = steamworkshop.DefaultRetries
+		}
+		backoff := config.SteamHttpRetryBackoff
+		if backoff == 0 {
+			backoff = steamworkshop.DefaultRetryBackoff
+		}
+		opts = append(opts, steamworkshop.WithRetries(retries, backoff))
+	}
+	if config.SteamApiBaseUrl != "" {
+		opts = append(opts, steamworkshop.WithApiBaseUrl(config.SteamApiBaseUrl))
+	}
+	if config.SteamApiKeyFile != "" {
+		apiKey, err := steamcmd.ReadSecretFile(config.SteamApiKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading SteamApiKeyFile: %w", err)
+		}
+		opts = append(opts, steamworkshop.WithApiKey(apiKey))
+	}
+	if config.SteamCommunityBaseUrl != "" {
+		opts = append(opts, steamworkshop.WithCommunityBaseUrl(config.SteamCommunityBaseUrl))
+	}
+	if config.SteamHttpProxy != "" {
+		proxyUrl, err := url.Parse(config.SteamHttpProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SteamHttpProxy: %w", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyUrl)
+		opts = append(opts, steamworkshop.WithHttpClient(&http.Client{Transport: transport}))
+	}
+
+	return steamworkshop.NewClient(opts...), nil
+}
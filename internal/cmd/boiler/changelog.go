@@ -0,0 +1,95 @@
+package boiler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/internal/boiler"
+	"github.com/spf13/cobra"
+)
+
+var changelogSince string
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [game]",
+	Short: "Prints the changelogs of the workshop items that were updated",
+	Long: `Prints the changelog entries of the workshop items of the game, or of all games if no game
+is given. By default, only the entries retrieved by the last update in which workshop items were
+updated are printed. Changelogs are retrieved by the update command when a workshop item has been updated.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		since, err := parseSince(changelogSince, time.Now())
+		if err != nil {
+			log.Fatalf("invalid --since: %v", err)
+		}
+
+		configFile, err := os.Open(configFilePath)
+		if err != nil {
+			log.Fatalf("failed to open config file: %v", err)
+		}
+		defer configFile.Close()
+		b, err := boiler.FromConfigReader(configFile)
+		if err != nil {
+			log.Fatalf("failed to read config: %v", err)
+		}
+
+		games := args
+		if len(games) == 0 {
+			games = b.GetGames()
+		}
+		for _, game := range games {
+			items, err := b.Changelog(game, since)
+			if err != nil {
+				log.Fatalf("failed to get changelog of %s: %v", game, err)
+			}
+			if len(items) == 0 {
+				continue
+			}
+
+			fmt.Printf("# %s\n", game)
+			for _, item := range items {
+				fmt.Printf("\n## %s (%d)\n", item.Title, item.Id)
+				for _, entry := range item.Entries {
+					fmt.Printf("\n%s\n", entry.Time.Local().Format("2006-01-02 15:04"))
+					if entry.Description != "" {
+						fmt.Println(entry.Description)
+					}
+				}
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// parseSince parses the value of --since, which is either a duration before now or a date.
+// Returns the zero time if the value is empty.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.DateOnly, time.DateTime, time.RFC3339} {
+		if since, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return since, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(
+		"%s is neither a duration, e.g. 72h, nor a date, e.g. 2024-03-12",
+		value,
+	)
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(
+		&changelogSince,
+		"since",
+		"",
+		`Print all entries published after this time instead of only those retrieved by the last
+update. Either a duration, e.g. 72h, or a date, e.g. 2024-03-12 or "2024-03-12 18:00:00".`,
+	)
+}
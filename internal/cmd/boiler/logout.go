@@ -1,13 +1,12 @@
 package boiler
 
 import (
-	"context"
+	"errors"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/MatthiasKunnen/boiler/internal/boiler"
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
 	"github.com/spf13/cobra"
 )
 
@@ -21,14 +20,8 @@ var logoutCmd = &cobra.Command{
 			username = args[0]
 		}
 
-		stopSig := make(chan os.Signal, 1)
-		signal.Notify(stopSig, os.Interrupt, syscall.SIGTERM)
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := cancelOnSignal()
 		defer cancel()
-		go func() {
-			<-stopSig
-			cancel()
-		}()
 
 		configFile, err := os.Open(configFilePath)
 		if err != nil {
@@ -41,7 +34,11 @@ var logoutCmd = &cobra.Command{
 		}
 
 		err = b.Logout(ctx)
-		if err != nil {
+		var cachedErr *steamcmd.CredentialsCachedError
+		switch {
+		case errors.As(err, &cachedErr):
+			log.Fatalf("logout did not remove the cached credentials: %v", err)
+		case err != nil:
 			log.Fatalf("failed to logout: %v", err)
 		}
 		log.Printf("Successfully logged out")
@@ -0,0 +1,62 @@
+package boiler
+
+import (
+	"log"
+	"os"
+
+	"github.com/MatthiasKunnen/boiler/internal/boiler"
+	"github.com/spf13/cobra"
+)
+
+var rollbackLoginUsername string
+var rollbackLogout bool
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback game",
+	Short: "Reinstalls the build of the game that was installed before the current one",
+	Long: `Downloads the depot manifests of the build that was installed before the current one.
+The game is pinned to these depots in the games configuration, so that updates do not undo the
+rollback. To update the game again, remove its Depots from the games configuration.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFile, err := os.Open(configFilePath)
+		if err != nil {
+			log.Fatalf("failed to open config file: %v", err)
+		}
+		defer configFile.Close()
+		b, err := boiler.FromConfigReader(
+			configFile,
+			boiler.WithLoginUsername(rollbackLoginUsername),
+		)
+		if err != nil {
+			log.Fatalf("failed to read config: %v", err)
+		}
+
+		ctx, cancel := cancelOnSignal()
+		defer cancel()
+
+		err = b.Rollback(ctx, args[0], boiler.RollbackOpts{
+			Logout: rollbackLogout,
+		})
+		if err != nil {
+			log.Fatalf("failed to roll back: %v", err)
+		}
+		log.Println("Rollback successful")
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().StringVar(
+		&rollbackLoginUsername,
+		"login-username",
+		"",
+		`Username to use to log in with steamcmd.`,
+	)
+	rollbackCmd.Flags().BoolVar(
+		&rollbackLogout,
+		"logout",
+		false,
+		`Log out of steamcmd after the operation completes.`,
+	)
+}
@@ -36,7 +36,10 @@ func init() {
 		boiler.ConfigFilePath,
 		"Path to the config file.",
 	)
+	rootCmd.AddCommand(changelogCmd)
 	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(workshopItemsCmd)
 }
@@ -0,0 +1,112 @@
+package boiler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MatthiasKunnen/boiler/internal/boiler"
+	"github.com/spf13/cobra"
+)
+
+var searchAdd int
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:   "search game query...",
+	Short: "Searches the workshop of the game and optionally adds a result to the games configuration",
+	Long: `Searches the workshop of the game for workshop items matching the query and prints the
+results, numbered from most to least relevant. Use --add with the number of a result to add it to
+the WorkshopItems of the game and retrieve its details and dependencies.
+Requires SteamApiKeyFile to be set in the config.
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if searchAdd < 0 || searchAdd > searchLimit {
+			log.Fatalf("--add must be between 1 and --limit (%d)", searchLimit)
+		}
+
+		configFile, err := os.Open(configFilePath)
+		if err != nil {
+			log.Fatalf("failed to open config file: %v", err)
+		}
+		defer configFile.Close()
+		b, err := boiler.FromConfigReader(configFile)
+		if err != nil {
+			log.Fatalf("failed to read config: %v", err)
+		}
+
+		ctx, cancel := cancelOnSignal()
+		defer cancel()
+
+		results, err := b.SearchWorkshop(ctx, args[0], strings.Join(args[1:], " "), searchLimit)
+		if err != nil {
+			log.Fatalf("failed to search: %v", err)
+		}
+		if len(results) == 0 {
+			log.Println("No workshop items found")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tID\tSIZE\tUPDATED\tTITLE")
+		for i, result := range results {
+			fmt.Fprintf(
+				w,
+				"%d\t%d\t%s\t%s\t%s\n",
+				i+1,
+				result.Id,
+				formatSize(result.FileSize),
+				result.TimeUpdated.Local().Format("2006-01-02"),
+				result.Title,
+			)
+		}
+		err = w.Flush()
+		if err != nil {
+			log.Fatalf("failed to print results: %v", err)
+		}
+
+		if searchAdd == 0 {
+			return
+		}
+		if searchAdd > len(results) {
+			log.Fatalf("--add %d is out of range, only %d results were found", searchAdd, len(results))
+		}
+		chosen := results[searchAdd-1]
+		err = b.AddWorkshopItem(ctx, args[0], chosen.Id)
+		if err != nil {
+			log.Fatalf("failed to add %d: %v", chosen.Id, err)
+		}
+	},
+}
+
+// formatSize formats the amount of bytes using binary prefixes, e.g. 1.5 MiB.
+func formatSize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	searchCmd.Flags().IntVar(
+		&searchAdd,
+		"add",
+		0,
+		`The number of the result to add to the WorkshopItems of the game.`,
+	)
+	searchCmd.Flags().IntVar(
+		&searchLimit,
+		"limit",
+		10,
+		`The maximum amount of results, at most 100.`,
+	)
+}
@@ -0,0 +1,29 @@
+package boiler
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// cancelOnSignal returns a context that is cancelled when SIGINT or SIGTERM is received, allowing
+// the command to stop steamcmd and save its progress. A second signal exits immediately.
+func cancelOnSignal() (context.Context, context.CancelFunc) {
+	stopSig := make(chan os.Signal, 2)
+	signal.Notify(stopSig, os.Interrupt, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopSig
+		log.Println("Stopping, press Ctrl+C again to exit immediately")
+		cancel()
+		<-stopSig
+		log.Println("Exiting immediately")
+		os.Exit(1)
+	}()
+	return ctx, func() {
+		signal.Stop(stopSig)
+		cancel()
+	}
+}
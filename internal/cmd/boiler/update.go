@@ -1,19 +1,23 @@
 package boiler
 
 import (
-	"context"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/MatthiasKunnen/boiler/internal/boiler"
 	"github.com/spf13/cobra"
 )
 
 var downloadUpToDate bool
+var dryRun bool
+var failOnUnavailable bool
+var forceGameUpdate bool
 var loginUsername string
 var logout bool
+var parallelism int
+var retries int
+var retryBackoff time.Duration
 var skipDatabaseUpdate bool
 var skipDownload bool
 var validate bool
@@ -21,9 +25,9 @@ var validate bool
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Updates all games, collections and workshop items in the games configuration",
-	Long: `By default, this downloads all games, and fetches information of the collections and
-workshop items in the games.json or dependencies thereof. All out-of-date workshop items that
-are in games.json or are dependencies will be downloaded.
+	Long: `By default, this updates all games of which a newer build is available, and fetches
+information of the collections and workshop items in the games.json or dependencies thereof.
+All out-of-date workshop items that are in games.json or are dependencies will be downloaded.
 `,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -37,31 +41,43 @@ are in games.json or are dependencies will be downloaded.
 			log.Fatalf("failed to read config: %v", err)
 		}
 
-		stopSig := make(chan os.Signal, 1)
-		signal.Notify(stopSig, os.Interrupt, syscall.SIGTERM)
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := cancelOnSignal()
 		defer cancel()
-		go func() {
-			<-stopSig
-			cancel()
-		}()
 
 		if !skipDatabaseUpdate {
-			err = b.UpdateDatabase(ctx, boiler.UpdateOpts{})
+			err = b.UpdateDatabase(ctx, boiler.UpdateOpts{
+				DryRun:            dryRun,
+				FailOnUnavailable: failOnUnavailable,
+			})
 			if err != nil {
 				log.Fatalf("failed to update: %v", err)
 			}
 		}
 		if !skipDownload {
-			err = b.Download(ctx, boiler.DownloadOpts{
+			opts := boiler.DownloadOpts{
 				DownloadUpToDate: downloadUpToDate,
+				DryRun:           dryRun,
+				ForceGameUpdate:  forceGameUpdate,
 				Logout:           logout,
+				RetryBackoff:     retryBackoff,
 				Validate:         validate,
-			})
+			}
+			// An explicit 0 overrides the config.
+			if cmd.Flags().Changed("parallelism") {
+				opts.Parallelism = &parallelism
+			}
+			if cmd.Flags().Changed("retries") {
+				opts.Retries = &retries
+			}
+			err = b.Download(ctx, opts)
 			if err != nil {
 				log.Fatalf("failed to update: %v", err)
 			}
 		}
+		if dryRun {
+			log.Println("Dry run complete, nothing was changed")
+			return
+		}
 		log.Println("Update successful")
 	},
 }
@@ -98,6 +114,48 @@ func init() {
 		false,
 		`Additionally, force download required workshop items that are up-to-date.`,
 	)
+	updateCmd.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		`Print the games and workshop items that would be downloaded, the steamcmd scripts, and
+the changes to the file system, without downloading or writing anything. steamcmd is only run
+to retrieve the current builds of the games, which are checked like during an update.`,
+	)
+	updateCmd.Flags().BoolVar(
+		&failOnUnavailable,
+		"fail-on-unavailable",
+		false,
+		`Fail before downloading if any of the workshop items, or their dependencies, has been
+removed, made private, or banned. By default, a warning is printed and the item is skipped.`,
+	)
+	updateCmd.Flags().BoolVar(
+		&forceGameUpdate,
+		"force-game-update",
+		false,
+		`Run app_update for all games, even if the installed build is current.`,
+	)
+	updateCmd.Flags().IntVar(
+		&parallelism,
+		"parallelism",
+		0,
+		`The amount of steamcmd instances that download workshop items concurrently.
+Defaults to DownloadParallelism in the config.`,
+	)
+	updateCmd.Flags().IntVar(
+		&retries,
+		"retries",
+		0,
+		`The amount of times workshop items that failed to download are retried.
+Defaults to DownloadRetries in the config.`,
+	)
+	updateCmd.Flags().DurationVar(
+		&retryBackoff,
+		"retry-backoff",
+		0,
+		`The time to wait before the first retry. Doubles with every subsequent retry.
+Defaults to DownloadRetryBackoff in the config.`,
+	)
 	updateCmd.Flags().BoolVar(
 		&validate,
 		"validate",
@@ -0,0 +1,473 @@
+package steamfake
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// WorkshopItem is a workshop item as known to the Steam web services.
+type WorkshopItem struct {
+	Id uint64
+	// The ID of the game that the workshop item relates to.
+	AppId       int
+	Title       string
+	TimeCreated time.Time
+	TimeUpdated time.Time
+	FileSize    uint64
+	// The workshop items that are listed as required items on the page of the item.
+	Requires      []uint64
+	Creator       uint64
+	Description   string
+	Subscriptions int
+	Tags          []string
+	Visibility    int
+	// If set, the item is banned for this reason.
+	BanReason string
+	// The apps, such as DLC, that are listed as required on the page of the item.
+	RequiresApps []StoreApp
+	// The change notes on the changelog page of the item, in any order.
+	Changelog []ChangeNote
+	// If set, this result is returned instead of the details of the item, e.g. 15 for access
+	// denied.
+	Result int
+}
+
+// ChangeNote is an entry of the changelog page of a workshop item.
+type ChangeNote struct {
+	Time        time.Time
+	Description string
+}
+
+// StoreApp is an app, such as a DLC, as linked to on the Steam store.
+type StoreApp struct {
+	Id   int
+	Name string
+}
+
+// Collection is a workshop collection as known to the Steam web services.
+type Collection struct {
+	Id uint64
+	// The workshop items in the collection.
+	Items []uint64
+	// The collections in the collection.
+	Collections []uint64
+}
+
+// Server is a fake of the Steam Web API and the Steam Community workshop pages. It serves
+// GetPublishedFileDetails, GetCollectionDetails, IPublishedFileService/GetDetails, and the
+// filedetails pages of the workshop items and collections that have been set.
+//
+// Its URL is to be used as both the API and the community base URL of the workshop client.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	workshopItems map[uint64]WorkshopItem
+	collections   map[uint64]Collection
+	requests      []string
+}
+
+// NewServer starts a fake Steam server that is closed when the test completes.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		workshopItems: make(map[uint64]WorkshopItem),
+		collections:   make(map[uint64]Collection),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"POST /ISteamRemoteStorage/GetPublishedFileDetails/v1/",
+		s.handlePublishedFileDetails,
+	)
+	mux.HandleFunc(
+		"POST /ISteamRemoteStorage/GetCollectionDetails/v1/",
+		s.handleCollectionDetails,
+	)
+	mux.HandleFunc("GET /IPublishedFileService/GetDetails/v1/", s.handleGetDetails)
+	mux.HandleFunc("GET /IPublishedFileService/QueryFiles/v1/", s.handleQueryFiles)
+	mux.HandleFunc("GET /sharedfiles/filedetails/", s.handleFileDetailsPage)
+	mux.HandleFunc("GET /sharedfiles/filedetails/changelog/{id}", s.handleChangelogPage)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// SetWorkshopItem adds or replaces the workshop item.
+func (s *Server) SetWorkshopItem(item WorkshopItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workshopItems[item.Id] = item
+}
+
+// RemoveWorkshopItem removes the workshop item, as if it was deleted by its author.
+func (s *Server) RemoveWorkshopItem(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.workshopItems, id)
+}
+
+// SetCollection adds or replaces the collection.
+func (s *Server) SetCollection(collection Collection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[collection.Id] = collection
+}
+
+// Requests returns the method and request URI of the requests that have been served, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// publishedFileIds returns the IDs in the publishedfileids[i] form values.
+func publishedFileIds(r *http.Request, countKey string) ([]uint64, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(r.PostForm.Get(countKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", countKey, err)
+	}
+	ids := make([]uint64, 0, count)
+	for i := range count {
+		id, err := strconv.ParseUint(r.PostForm.Get(fmt.Sprintf("publishedfileids[%d]", i)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid publishedfileids[%d]: %w", i, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *Server) handlePublishedFileDetails(w http.ResponseWriter, r *http.Request) {
+	ids, err := publishedFileIds(r, "itemcount")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	details := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		item, ok := s.workshopItems[id]
+		if !ok {
+			item.Result = 9
+		}
+		if item.Result != 0 {
+			details = append(details, map[string]any{
+				"publishedfileid": strconv.FormatUint(id, 10),
+				"result":          item.Result,
+			})
+			continue
+		}
+		details = append(details, s.fileDetails(id, item))
+	}
+
+	writeJson(w, map[string]any{"response": map[string]any{
+		"result":               1,
+		"resultcount":          len(details),
+		"publishedfiledetails": details,
+	}})
+}
+
+// fileDetails returns the details of the workshop item as returned by GetPublishedFileDetails.
+func (s *Server) fileDetails(id uint64, item WorkshopItem) map[string]any {
+	tags := make([]map[string]any, 0, len(item.Tags))
+	for _, tag := range item.Tags {
+		tags = append(tags, map[string]any{"tag": tag})
+	}
+	banned := 0
+	if item.BanReason != "" {
+		banned = 1
+	}
+	return map[string]any{
+		"publishedfileid": strconv.FormatUint(id, 10),
+		"result":          1,
+		"creator":         strconv.FormatUint(item.Creator, 10),
+		"creator_app_id":  item.AppId,
+		"consumer_app_id": item.AppId,
+		"file_size":       strconv.FormatUint(item.FileSize, 10),
+		"hcontent_file":   strconv.FormatInt(item.TimeUpdated.Unix(), 10),
+		"preview_url":     fmt.Sprintf("%s/preview/%d.jpg", s.URL, id),
+		"title":           item.Title,
+		"description":     item.Description,
+		"time_created":    item.TimeCreated.Unix(),
+		"time_updated":    item.TimeUpdated.Unix(),
+		"visibility":      item.Visibility,
+		"banned":          banned,
+		"ban_reason":      item.BanReason,
+		"subscriptions":   item.Subscriptions,
+		"tags":            tags,
+	}
+}
+
+// handleGetDetails serves IPublishedFileService/GetDetails, which requires an API key. The
+// required items of the workshop items are returned as their children.
+func (s *Server) handleGetDetails(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("key") == "" {
+		http.Error(w, "missing key", http.StatusForbidden)
+		return
+	}
+	var ids []uint64
+	for i := 0; query.Has(fmt.Sprintf("publishedfileids[%d]", i)); i++ {
+		id, err := strconv.ParseUint(query.Get(fmt.Sprintf("publishedfileids[%d]", i)), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	details := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		item, ok := s.workshopItems[id]
+		if !ok {
+			item.Result = 9
+		}
+		if item.Result != 0 {
+			details = append(details, map[string]any{
+				"publishedfileid": strconv.FormatUint(id, 10),
+				"result":          item.Result,
+			})
+			continue
+		}
+		details = append(details, s.publishedFileServiceDetails(id, item))
+	}
+
+	writeJson(w, map[string]any{"response": map[string]any{
+		"publishedfiledetails": details,
+	}})
+}
+
+// publishedFileServiceDetails returns the details of the workshop item as returned by the
+// endpoints of IPublishedFileService, which differ slightly from those of ISteamRemoteStorage.
+func (s *Server) publishedFileServiceDetails(id uint64, item WorkshopItem) map[string]any {
+	detail := s.fileDetails(id, item)
+	delete(detail, "creator_app_id")
+	delete(detail, "consumer_app_id")
+	delete(detail, "description")
+	detail["creator_appid"] = item.AppId
+	detail["consumer_appid"] = item.AppId
+	detail["file_description"] = item.Description
+	detail["banned"] = item.BanReason != ""
+	children := make([]map[string]any, 0, len(item.Requires))
+	for _, child := range item.Requires {
+		children = append(children, map[string]any{
+			"publishedfileid": strconv.FormatUint(child, 10),
+			"sortorder":       len(children),
+			"file_type":       0,
+		})
+	}
+	detail["num_children"] = len(children)
+	detail["children"] = children
+	return detail
+}
+
+// handleQueryFiles serves IPublishedFileService/QueryFiles, which requires an API key. The
+// workshop items of the app whose title contains the search text are returned, ordered by ID.
+func (s *Server) handleQueryFiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("key") == "" {
+		http.Error(w, "missing key", http.StatusForbidden)
+		return
+	}
+	appId, err := strconv.Atoi(query.Get("appid"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	numPerPage := 1
+	if query.Has("numperpage") {
+		numPerPage, err = strconv.Atoi(query.Get("numperpage"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	searchText := strings.ToLower(query.Get("search_text"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []uint64
+	for id, item := range s.workshopItems {
+		if item.AppId == appId && strings.Contains(strings.ToLower(item.Title), searchText) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	details := make([]map[string]any, 0, min(len(ids), numPerPage))
+	for _, id := range ids[:min(len(ids), numPerPage)] {
+		details = append(details, s.publishedFileServiceDetails(id, s.workshopItems[id]))
+	}
+	writeJson(w, map[string]any{"response": map[string]any{
+		"total":                len(ids),
+		"publishedfiledetails": details,
+	}})
+}
+
+func (s *Server) handleCollectionDetails(w http.ResponseWriter, r *http.Request) {
+	ids, err := publishedFileIds(r, "collectioncount")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	details := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		collection, ok := s.collections[id]
+		if !ok {
+			details = append(details, map[string]any{
+				"publishedfileid": strconv.FormatUint(id, 10),
+				"result":          9,
+			})
+			continue
+		}
+		children := make([]map[string]any, 0, len(collection.Items)+len(collection.Collections))
+		for _, child := range collection.Items {
+			children = append(children, map[string]any{
+				"publishedfileid": strconv.FormatUint(child, 10),
+				"sortorder":       len(children) + 1,
+				"filetype":        0,
+			})
+		}
+		for _, child := range collection.Collections {
+			children = append(children, map[string]any{
+				"publishedfileid": strconv.FormatUint(child, 10),
+				"sortorder":       len(children) + 1,
+				"filetype":        2,
+			})
+		}
+		details = append(details, map[string]any{
+			"publishedfileid": strconv.FormatUint(id, 10),
+			"result":          1,
+			"children":        children,
+		})
+	}
+
+	writeJson(w, map[string]any{"response": map[string]any{
+		"result":            1,
+		"resultcount":       len(details),
+		"collectiondetails": details,
+	}})
+}
+
+var fileDetailsPage = template.Must(template.New("filedetails").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Steam Workshop::{{.Title}}</title></head>
+<body>
+<div class="workshopItemTitle">{{.Title}}</div>
+{{- if .RequiresApps}}
+<div class="requiredDLCContainer">
+{{- range .RequiresApps}}
+<div class="requiredDLCItem">
+<a href="https://store.steampowered.com/app/{{.Id}}/" target="_blank"><img class="requiredDLCImage" src="capsule.jpg"></a>
+<div class="requiredDLCName"><a href="https://store.steampowered.com/app/{{.Id}}/" target="_blank">{{.Name}}</a></div>
+</div>
+{{- end}}
+</div>
+{{- end}}
+{{- if .Requires}}
+<div class="requiredItemsContainer" id="RequiredItems">
+{{- range .Requires}}
+<a href="https://steamcommunity.com/workshop/filedetails/?id={{.Id}}" target="_blank">
+<div class="requiredItem">{{.Title}}</div>
+</a>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+func (s *Server) handleFileDetailsPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.workshopItems[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	page := struct {
+		Title        string
+		Requires     []WorkshopItem
+		RequiresApps []StoreApp
+	}{Title: item.Title, RequiresApps: item.RequiresApps}
+	for _, requiredId := range item.Requires {
+		required := s.workshopItems[requiredId]
+		required.Id = requiredId
+		page.Requires = append(page.Requires, required)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = fileDetailsPage.Execute(w, page)
+}
+
+var changelogPage = template.Must(template.New("changelog").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Steam Workshop::{{.Title}} :: Change Notes</title></head>
+<body>
+<div class="workshopItemTitle">{{.Title}}</div>
+{{- range .Changelog}}
+<div class="detailBox workshopAnnouncement noFooter changeLogCtn">
+<div class="changelog headline">Update: {{.Time.Format "2 Jan, 2006 @ 3:04pm"}}</div>
+<p id="{{.Time.Unix}}">{{.Description}}</p>
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+func (s *Server) handleChangelogPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.workshopItems[id]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = changelogPage.Execute(w, item)
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.MarshalWrite(w, v)
+}
@@ -0,0 +1,451 @@
+// Package steamfake provides stand-ins for steamcmd and the Steam web services, so that boiler
+// can be tested end to end without network access or a Steam installation.
+package steamfake
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+	"github.com/go-json-experiment/json"
+)
+
+// stateDirEnv is set for the fake steamcmd process and points to the directory that contains
+// the state of the fake.
+const stateDirEnv = "BOILER_FAKE_STEAMCMD"
+
+// App is an app that can be installed using app_update.
+type App struct {
+	Name string
+	// The build ID of every branch, keyed by branch name. The public branch is
+	// [steamcmd.PublicBranch].
+	Branches map[string]string
+	// The files of the app, keyed by their path relative to the install dir.
+	Files map[string]string
+	// The branches that contain a depot of a DLC, keyed by the app ID of the DLC.
+	Dlc map[int][]string
+}
+
+// WorkshopContent is the content of a workshop item that can be downloaded using
+// workshop_download_item.
+type WorkshopContent struct {
+	// The files of the workshop item, keyed by their path relative to the content dir.
+	Files map[string]string
+	// If set, downloading the item fails with this reason.
+	FailReason string
+}
+
+type steamcmdState struct {
+	Apps          map[int]App
+	WorkshopItems map[uint64]WorkshopContent
+}
+
+// Steamcmd is a fake steamcmd executable. It interprets runscripts the way steamcmd does and
+// writes the content and manifests of the apps and workshop items that have been set.
+//
+// The executable is the test binary itself, so the tests using it must call
+// [RunIfSteamcmd] from TestMain.
+type Steamcmd struct {
+	// The path of the executable.
+	Path string
+	dir  string
+
+	mu    sync.Mutex
+	state steamcmdState
+	t     testing.TB
+}
+
+// NewSteamcmd creates a fake steamcmd executable in a temporary directory.
+func NewSteamcmd(t testing.TB) *Steamcmd {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("error finding test executable: %v", err)
+	}
+
+	dir := t.TempDir()
+	s := &Steamcmd{
+		Path: filepath.Join(dir, "steamcmd"),
+		dir:  dir,
+		state: steamcmdState{
+			Apps:          make(map[int]App),
+			WorkshopItems: make(map[uint64]WorkshopContent),
+		},
+		t: t,
+	}
+	err = os.MkdirAll(filepath.Join(dir, "scripts"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := fmt.Sprintf(
+		"#!/bin/sh\n%s=%s exec %s \"$@\"\n",
+		stateDirEnv,
+		shellQuote(dir),
+		shellQuote(executable),
+	)
+	err = os.WriteFile(s.Path, []byte(script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.save()
+	return s
+}
+
+// SetApp makes the app available to app_update and app_info_print.
+func (s *Steamcmd) SetApp(appId int, app App) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Apps[appId] = app
+	s.save()
+}
+
+// SetWorkshopItem makes the workshop item available to workshop_download_item.
+func (s *Steamcmd) SetWorkshopItem(workshopItemId uint64, content WorkshopContent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.WorkshopItems[workshopItemId] = content
+	s.save()
+}
+
+// Scripts returns the runscripts that have been executed, in order.
+func (s *Steamcmd) Scripts() []string {
+	s.t.Helper()
+	entries, err := os.ReadDir(filepath.Join(s.dir, "scripts"))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	scripts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		script, err := os.ReadFile(filepath.Join(s.dir, "scripts", entry.Name()))
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		scripts = append(scripts, string(script))
+	}
+	return scripts
+}
+
+func (s *Steamcmd) save() {
+	s.t.Helper()
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(s.dir, "state.json"), data, 0600)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// RunIfSteamcmd runs the fake steamcmd and exits if the process was started as one.
+// It must be called at the start of TestMain.
+func RunIfSteamcmd() {
+	stateDir := os.Getenv(stateDirEnv)
+	if stateDir == "" {
+		return
+	}
+	os.Exit(runSteamcmd(stateDir, os.Args[1:], os.Stdout))
+}
+
+func runSteamcmd(stateDir string, args []string, stdout io.Writer) int {
+	if len(args) != 2 || args[0] != "+runscript" {
+		_, _ = fmt.Fprintf(stdout, "fake steamcmd only supports +runscript, got %q\n", args)
+		return 1
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, "state.json"))
+	if err != nil {
+		_, _ = fmt.Fprintln(stdout, err)
+		return 1
+	}
+	var state steamcmdState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		_, _ = fmt.Fprintln(stdout, err)
+		return 1
+	}
+
+	script, err := os.ReadFile(args[1])
+	if err != nil {
+		_, _ = fmt.Fprintln(stdout, err)
+		return 1
+	}
+	err = recordScript(stateDir, script)
+	if err != nil {
+		_, _ = fmt.Fprintln(stdout, err)
+		return 1
+	}
+
+	run := &fakeRun{state: state, stdout: stdout}
+	scanner := bufio.NewScanner(strings.NewReader(string(script)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		err := run.exec(strings.Fields(line))
+		if err != nil {
+			_, _ = fmt.Fprintln(stdout, err)
+			return 8
+		}
+		if run.quit {
+			break
+		}
+	}
+	return 0
+}
+
+// recordScript stores the script under the next free number in the scripts directory.
+func recordScript(stateDir string, script []byte) error {
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(
+			filepath.Join(stateDir, "scripts", fmt.Sprintf("%04d.txt", i)),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+			0600,
+		)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(script)
+		return errors.Join(err, f.Close())
+	}
+}
+
+type fakeRun struct {
+	state      steamcmdState
+	stdout     io.Writer
+	installDir string
+	quit       bool
+}
+
+// exec executes a single command. An error stops the script, like @ShutdownOnFailedCommand.
+func (r *fakeRun) exec(fields []string) error {
+	switch fields[0] {
+	case "@ShutdownOnFailedCommand", "@sSteamCmdForcePlatformType", "set_steam_guard_code",
+		"app_info_update", "logout":
+		return nil
+	case "force_install_dir":
+		r.installDir = fields[1]
+		return nil
+	case "login":
+		_, _ = fmt.Fprintf(r.stdout, "Logging in user '%s' [U:1:0] to Steam Public...OK\n", fields[1])
+		return nil
+	case "quit":
+		r.quit = true
+		return nil
+	case "app_update":
+		return r.appUpdate(fields[1:])
+	case "app_info_print":
+		return r.appInfoPrint(fields[1])
+	case "workshop_download_item":
+		return r.workshopDownloadItem(fields[1], fields[2])
+	default:
+		return fmt.Errorf("Command not found: %s", fields[0])
+	}
+}
+
+func (r *fakeRun) appUpdate(args []string) error {
+	appId, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+	branch := steamcmd.PublicBranch
+	if i := slices.Index(args, "-beta"); i >= 0 && i+1 < len(args) {
+		branch = args[i+1]
+	}
+
+	app, ok := r.state.Apps[appId]
+	buildId, branchOk := app.Branches[branch]
+	if !ok || !branchOk {
+		return fmt.Errorf("Error! App '%d' state is 0x202 after update job.", appId)
+	}
+
+	err = writeFiles(r.installDir, app.Files)
+	if err != nil {
+		return err
+	}
+	appState := vdf.Object{
+		{Key: "appid", String: strconv.Itoa(appId)},
+		{Key: "name", String: app.Name},
+		{Key: "buildid", String: buildId},
+		{Key: "InstalledDepots", Object: vdf.Object{}},
+	}
+	if branch != steamcmd.PublicBranch {
+		appState = append(appState, vdf.Pair{
+			Key:    "UserConfig",
+			Object: vdf.Object{{Key: "BetaKey", String: branch}},
+		})
+	}
+	err = writeVdf(
+		filepath.Join(r.installDir, "steamapps", fmt.Sprintf("appmanifest_%d.acf", appId)),
+		vdf.Object{{Key: "AppState", Object: appState}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Success! App '%d' fully installed.\n", appId)
+	return nil
+}
+
+func (r *fakeRun) appInfoPrint(appIdArg string) error {
+	appId, err := strconv.Atoi(appIdArg)
+	if err != nil {
+		return err
+	}
+	app, ok := r.state.Apps[appId]
+	if !ok {
+		_, _ = fmt.Fprintf(r.stdout, "No app info for AppID %d found\n", appId)
+		return nil
+	}
+
+	branches := vdf.Object{}
+	for _, name := range slices.Sorted(maps.Keys(app.Branches)) {
+		branches = append(branches, vdf.Pair{
+			Key:    name,
+			Object: vdf.Object{{Key: "buildid", String: app.Branches[name]}},
+		})
+	}
+	depots := vdf.Object{}
+	for i, dlcAppId := range slices.Sorted(maps.Keys(app.Dlc)) {
+		manifests := vdf.Object{}
+		for _, branch := range app.Dlc[dlcAppId] {
+			manifests = append(manifests, vdf.Pair{
+				Key:    branch,
+				Object: vdf.Object{{Key: "gid", String: strconv.Itoa(dlcAppId)}},
+			})
+		}
+		depots = append(depots, vdf.Pair{Key: strconv.Itoa(appId + i + 1), Object: vdf.Object{
+			{Key: "dlcappid", String: strconv.Itoa(dlcAppId)},
+			{Key: "manifests", Object: manifests},
+		}})
+	}
+	depots = append(depots, vdf.Pair{Key: "branches", Object: branches})
+	info := vdf.Object{{Key: appIdArg, Object: vdf.Object{
+		{Key: "common", Object: vdf.Object{{Key: "name", String: app.Name}}},
+		{Key: "depots", Object: depots},
+	}}}
+	_, err = info.WriteTo(r.stdout)
+	return err
+}
+
+func (r *fakeRun) workshopDownloadItem(gameIdArg string, itemIdArg string) error {
+	gameId, err := strconv.Atoi(gameIdArg)
+	if err != nil {
+		return err
+	}
+	itemId, err := strconv.ParseUint(itemIdArg, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	content, ok := r.state.WorkshopItems[itemId]
+	switch {
+	case !ok:
+		_, _ = fmt.Fprintf(r.stdout, "ERROR! Download item %d failed (File Not Found).\n", itemId)
+		return nil
+	case content.FailReason != "":
+		_, _ = fmt.Fprintf(
+			r.stdout,
+			"ERROR! Download item %d failed (%s).\n",
+			itemId,
+			content.FailReason,
+		)
+		return nil
+	}
+
+	// The content of the item is replaced.
+	contentDir := steamcmd.WorkshopItemContentDir(r.installDir, gameId, itemId)
+	err = os.RemoveAll(contentDir)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(contentDir, 0755)
+	if err != nil {
+		return err
+	}
+	err = writeFiles(contentDir, content.Files)
+	if err != nil {
+		return err
+	}
+	var size int
+	for _, data := range content.Files {
+		size += len(data)
+	}
+
+	manifestPath := filepath.Join(
+		r.installDir,
+		"steamapps/workshop",
+		fmt.Sprintf("appworkshop_%d.acf", gameId),
+	)
+	appWorkshop := vdf.Object{{Key: "appid", String: gameIdArg}}
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		manifest, err := vdf.ParseBytes(data)
+		if err != nil {
+			return err
+		}
+		appWorkshop, _ = manifest.LookupObject("AppWorkshop")
+	}
+	installed, ok := appWorkshop.LookupObject("WorkshopItemsInstalled")
+	if !ok {
+		installed = vdf.Object{}
+	}
+	installed = installed.Set(vdf.Pair{Key: itemIdArg, Object: vdf.Object{
+		{Key: "size", String: strconv.Itoa(size)},
+	}})
+	appWorkshop = appWorkshop.Set(vdf.Pair{Key: "WorkshopItemsInstalled", Object: installed})
+	err = writeVdf(manifestPath, vdf.Object{{Key: "AppWorkshop", Object: appWorkshop}})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(
+		r.stdout,
+		"Success. Downloaded item %d to \"%s\" (%d bytes)\n",
+		itemId,
+		contentDir,
+		size,
+	)
+	return nil
+}
+
+func writeFiles(dir string, files map[string]string) error {
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(p), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(p, []byte(data), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeVdf(p string, o vdf.Object) error {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, vdf.Marshal(o), 0644)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
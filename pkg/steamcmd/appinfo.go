@@ -0,0 +1,283 @@
+package steamcmd
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+)
+
+// PublicBranch is the name of the branch that is installed when no beta branch is given.
+const PublicBranch = "public"
+
+type AppInfoOpts struct {
+	AppIds []int
+	// If empty, anonymous will be used. Some apps only provide information to users that own
+	// them.
+	LoginUsername string
+	SteamCmdPath  string
+	// See [Opts.IsolatedHome].
+	IsolatedHome bool
+	// See [Opts.Authenticator].
+	Authenticator Authenticator
+	// See [Opts.ConfirmationTimeout].
+	ConfirmationTimeout time.Duration
+	// See [Opts.RunTimeout].
+	RunTimeout time.Duration
+	// See [Opts.InactivityTimeout].
+	InactivityTimeout time.Duration
+}
+
+// AppInfo retrieves the information of the apps using app_info_print.
+// Returns the information for every app, keyed by app ID. Apps for which steamcmd printed no
+// information are missing from the result.
+// The output of steamcmd is printed up to the information of the first app, which is only
+// captured.
+func AppInfo(ctx context.Context, opts AppInfoOpts) (map[int]vdf.Object, error) {
+	commands := []string{"app_info_update 1"}
+	for _, appId := range opts.AppIds {
+		commands = append(commands, "app_info_print "+strconv.Itoa(appId))
+	}
+
+	var output bytes.Buffer
+	r := newRunner(
+		withSteamcmd(opts.SteamCmdPath),
+		withIsolatedHome(opts.IsolatedHome),
+		withAuthenticator(opts.Authenticator, opts.ConfirmationTimeout),
+		withTimeouts(opts.RunTimeout, opts.InactivityTimeout),
+		withOutput(&output),
+		withConsole(&appInfoConsole{w: os.Stdout}),
+	)
+	err := r.Add(runOpts{
+		LoginUsername: opts.LoginUsername,
+		ExtraCommands: commands,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = r.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]vdf.Object, len(opts.AppIds))
+	for _, appId := range opts.AppIds {
+		info, err := parseAppInfo(output.Bytes(), appId)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing app info of %d: %w", appId, err)
+		}
+		if info != nil {
+			result[appId] = info
+		}
+	}
+
+	return result, nil
+}
+
+// appInfoConsole prints the output of steamcmd until app_info_print starts printing the
+// information of the first app. Everything after that is discarded, since the information of an
+// app is thousands of lines long. The login, including its prompts, remains visible.
+type appInfoConsole struct {
+	w       io.Writer
+	line    []byte
+	discard bool
+}
+
+var appInfoStart = []byte("AppID : ")
+
+func (c *appInfoConsole) Write(p []byte) (int, error) {
+	if c.discard {
+		return len(p), nil
+	}
+
+	rest := p
+	for len(rest) > 0 {
+		end := bytes.IndexByte(rest, '\n')
+		if end == -1 {
+			c.line = append(c.line, rest...)
+			break
+		}
+		c.line = append(c.line, rest[:end+1]...)
+		rest = rest[end+1:]
+		if bytes.Contains(c.line, appInfoStart) {
+			c.discard = true
+			_, err := c.w.Write(p[:len(p)-len(rest)])
+			return len(p), err
+		}
+		c.line = c.line[:0]
+	}
+
+	_, err := c.w.Write(p)
+	return len(p), err
+}
+
+// parseAppInfo extracts the information of the app from the output of app_info_print.
+// The information starts with a line containing the quoted app ID followed by an object, e.g.:
+//
+//	AppID : 233780, change number : 26451212/0, last change : Tue Oct  1 12:00:00 2024
+//	"233780"
+//	{
+//		...
+//	}
+//
+// Returns nil if the output does not contain information about the app.
+func parseAppInfo(output []byte, appId int) (vdf.Object, error) {
+	header := strconv.Quote(strconv.Itoa(appId))
+	var block bytes.Buffer
+	depth := 0
+	found := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 1024*1024)
+scan:
+	for scanner.Scan() {
+		line := strings.TrimSpace(ansiEscapeRe.ReplaceAllString(scanner.Text(), ""))
+		if !found {
+			// The app ID can be preceded by the prompt when the output is not a terminal.
+			found = line == header || strings.HasSuffix(line, ">"+header)
+			if found {
+				block.WriteString(header + "\n")
+			}
+			continue
+		}
+
+		block.WriteString(line + "\n")
+		// Braces are always on their own line in the output of app_info_print.
+		switch line {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				break scan
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	info, err := vdf.Parse(&block)
+	if err != nil {
+		return nil, err
+	}
+	object, _ := info.LookupObject(strconv.Itoa(appId))
+	return object, nil
+}
+
+// BranchBuildId returns the current build ID of the branch from the app information returned by
+// [AppInfo]. If branch is empty, the public branch is used.
+func BranchBuildId(appInfo vdf.Object, branch string) (string, bool) {
+	if branch == "" {
+		branch = PublicBranch
+	}
+	return appInfo.LookupString("depots", "branches", branch, "buildid")
+}
+
+// BranchDlc returns the IDs of the DLC of which the branch contains depots, according to the app
+// information returned by [AppInfo]. If branch is empty, the public branch is used.
+func BranchDlc(appInfo vdf.Object, branch string) []int {
+	if branch == "" {
+		branch = PublicBranch
+	}
+	depots, _ := appInfo.LookupObject("depots")
+
+	var result []int
+	for _, depot := range depots {
+		if !depot.IsObject() {
+			continue
+		}
+		dlcAppIdValue, ok := depot.Object.LookupString("dlcappid")
+		if !ok {
+			continue
+		}
+		dlcAppId, err := strconv.Atoi(dlcAppIdValue)
+		if err != nil {
+			continue
+		}
+		// Depots of branches that require a password list their manifests as encrypted.
+		_, inBranch := depot.Object.Lookup("manifests", branch)
+		_, inEncryptedBranch := depot.Object.Lookup("encryptedmanifests", branch)
+		if (inBranch || inEncryptedBranch) && !slices.Contains(result, dlcAppId) {
+			result = append(result, dlcAppId)
+		}
+	}
+	return result
+}
+
+// AppManifest contains the state of an installed app as stored by steamcmd in
+// steamapps/appmanifest_<appid>.acf.
+type AppManifest struct {
+	AppId   int
+	BuildId string
+	// The beta branch that is installed. Empty if the public branch is installed.
+	BetaKey string
+	// The manifests of the installed depots.
+	Depots []DepotManifest
+}
+
+// ReadAppManifest reads the manifest of the app installed in installDir, the directory passed to
+// force_install_dir.
+func ReadAppManifest(installDir string, appId int) (AppManifest, error) {
+	manifestPath := filepath.Join(
+		installDir,
+		"steamapps",
+		fmt.Sprintf("appmanifest_%d.acf", appId),
+	)
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return AppManifest{}, fmt.Errorf("error opening app manifest: %w", err)
+	}
+	defer f.Close()
+
+	var manifest struct {
+		AppState struct {
+			AppId      int
+			BuildId    string
+			UserConfig struct {
+				BetaKey string
+			}
+			InstalledDepots map[string]struct {
+				Manifest uint64
+			}
+		}
+	}
+	err = vdf.UnmarshalRead(f, &manifest)
+	if err != nil {
+		return AppManifest{}, fmt.Errorf("error parsing %s: %w", manifestPath, err)
+	}
+
+	result := AppManifest{
+		AppId:   manifest.AppState.AppId,
+		BuildId: manifest.AppState.BuildId,
+		BetaKey: manifest.AppState.UserConfig.BetaKey,
+	}
+	for key, depot := range manifest.AppState.InstalledDepots {
+		depotId, err := strconv.Atoi(key)
+		if err != nil {
+			return AppManifest{}, fmt.Errorf("invalid depot ID %q in %s", key, manifestPath)
+		}
+		result.Depots = append(result.Depots, DepotManifest{
+			DepotId:    depotId,
+			ManifestId: depot.Manifest,
+		})
+	}
+	slices.SortFunc(result.Depots, func(a, b DepotManifest) int {
+		return cmp.Compare(a.DepotId, b.DepotId)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppInfo(t *testing.T) {
+	output, err := filepath.Abs("testdata/app_info_print.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appInfo, err := steamcmd.AppInfo(context.Background(), steamcmd.AppInfoOpts{
+		AppIds:       []int{233780, 107410},
+		SteamCmdPath: writeFakeSteamcmd(t, "#!/bin/sh\ncat '"+output+"'\n"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, appInfo, 1, "107410 is not in the output")
+
+	name, _ := appInfo[233780].LookupString("common", "name")
+	assert.Equal(t, "Arma 3 Server", name)
+
+	buildId, ok := steamcmd.BranchBuildId(appInfo[233780], "")
+	assert.True(t, ok)
+	assert.Equal(t, "14521731", buildId)
+
+	buildId, ok = steamcmd.BranchBuildId(appInfo[233780], "creatordlc")
+	assert.True(t, ok)
+	assert.Equal(t, "14521755", buildId)
+
+	_, ok = steamcmd.BranchBuildId(appInfo[233780], "profiling")
+	assert.False(t, ok)
+
+	assert.Empty(t, steamcmd.BranchDlc(appInfo[233780], ""))
+	assert.Equal(t, []int{1042220, 1681170}, steamcmd.BranchDlc(appInfo[233780], "creatordlc"))
+}
+
+func TestAppInfo_OutputNotPrinted(t *testing.T) {
+	output, err := filepath.Abs("testdata/app_info_print.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	originalStdout := os.Stdout
+	os.Stdout = stdout
+	defer func() {
+		os.Stdout = originalStdout
+	}()
+
+	appInfo, err := steamcmd.AppInfo(context.Background(), steamcmd.AppInfoOpts{
+		AppIds:       []int{233780},
+		SteamCmdPath: writeFakeSteamcmd(t, "#!/bin/sh\ncat '"+output+"'\n"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, appInfo, 1)
+
+	printed, err := os.ReadFile(stdout.Name())
+	assert.NoError(t, err)
+	assert.Contains(t, string(printed), "Steam>app_info_print 233780")
+	assert.NotContains(t, string(printed), "Arma 3 Server")
+}
+
+func TestReadAppManifest(t *testing.T) {
+	manifest, err := steamcmd.ReadAppManifest("testdata/Arma3", 233780)
+	assert.NoError(t, err)
+	assert.Equal(t, steamcmd.AppManifest{
+		AppId:   233780,
+		BuildId: "14521731",
+		BetaKey: "creatordlc",
+		Depots: []steamcmd.DepotManifest{
+			{DepotId: 233781, ManifestId: 6183564618733453744},
+			{DepotId: 233782, ManifestId: 1893426745836459121},
+		},
+	}, manifest)
+
+	_, err = steamcmd.ReadAppManifest("testdata/Arma3", 107410)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
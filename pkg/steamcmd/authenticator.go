@@ -0,0 +1,136 @@
+package steamcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Authenticator supplies the credentials that steamcmd asks for while logging in.
+type Authenticator interface {
+	// Password returns the password of the given user.
+	Password(ctx context.Context, username string) (string, error)
+	// SteamGuardCode returns a Steam Guard code for the given user. The code is either received
+	// by email or generated by a mobile authenticator.
+	SteamGuardCode(ctx context.Context, username string) (string, error)
+}
+
+// SteamGuardCodeGenerator can be implemented by an [Authenticator] that generates Steam Guard
+// codes without user interaction, e.g. from the shared secret of a mobile authenticator.
+// The code is then passed to steamcmd using set_steam_guard_code before logging in, so that the
+// login does not wait for a confirmation in the Steam Mobile app.
+type SteamGuardCodeGenerator interface {
+	GenerateSteamGuardCode() (string, error)
+}
+
+// TerminalAuthenticator prompts the user for the credentials.
+// The prompts are written to stderr and the answers are read from stdin.
+type TerminalAuthenticator struct{}
+
+func (TerminalAuthenticator) Password(_ context.Context, username string) (string, error) {
+	_, _ = fmt.Fprintf(os.Stderr, "\nSteam password for %s: ", username)
+	return readSecret(os.Stdin)
+}
+
+func (TerminalAuthenticator) SteamGuardCode(_ context.Context, username string) (string, error) {
+	_, _ = fmt.Fprintf(os.Stderr, "\nSteam Guard code for %s: ", username)
+	return readLine(os.Stdin)
+}
+
+// FileAuthenticator reads the credentials from files.
+// Leading and trailing whitespace is removed from the file contents.
+// If a path is empty, the respective credential is not available.
+type FileAuthenticator struct {
+	PasswordFile       string
+	SteamGuardCodeFile string
+}
+
+func (a FileAuthenticator) Password(_ context.Context, _ string) (string, error) {
+	return readSecretFile("password", a.PasswordFile)
+}
+
+func (a FileAuthenticator) SteamGuardCode(_ context.Context, _ string) (string, error) {
+	return readSecretFile("Steam Guard code", a.SteamGuardCodeFile)
+}
+
+const (
+	DefaultPasswordEnv       = "BOILER_STEAM_PASSWORD"
+	DefaultSteamGuardCodeEnv = "BOILER_STEAM_GUARD_CODE"
+)
+
+// EnvAuthenticator reads the credentials from environment variables.
+// If a variable name is empty, [DefaultPasswordEnv] or [DefaultSteamGuardCodeEnv] is used.
+type EnvAuthenticator struct {
+	PasswordVar       string
+	SteamGuardCodeVar string
+}
+
+func (a EnvAuthenticator) Password(_ context.Context, _ string) (string, error) {
+	return lookupEnv(a.PasswordVar, DefaultPasswordEnv)
+}
+
+func (a EnvAuthenticator) SteamGuardCode(_ context.Context, _ string) (string, error) {
+	return lookupEnv(a.SteamGuardCodeVar, DefaultSteamGuardCodeEnv)
+}
+
+// ErrCredentialUnavailable is returned by an [Authenticator] that cannot supply the requested
+// credential.
+var ErrCredentialUnavailable = errors.New("credential not available")
+
+// ReadSecretFile reads a secret, such as a password, from a file. Leading and trailing whitespace
+// is removed.
+func ReadSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readSecretFile(name string, path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("%s: %w", name, ErrCredentialUnavailable)
+	}
+	secret, err := ReadSecretFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s file: %w", name, err)
+	}
+	return secret, nil
+}
+
+func lookupEnv(name string, fallback string) (string, error) {
+	if name == "" {
+		name = fallback
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s: %w", name, ErrCredentialUnavailable)
+	}
+	return value, nil
+}
+
+// readLine reads a single line from r, one byte at a time so that no input after the line is
+// consumed.
+func readLine(r io.Reader) (string, error) {
+	var sb strings.Builder
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				break
+			}
+			sb.WriteByte(b[0])
+		}
+		if errors.Is(err, io.EOF) && sb.Len() > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(sb.String(), "\r"), nil
+}
@@ -0,0 +1,62 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBetaPasswordScript stores the runscript in the install dir and fails while echoing the
+// password, in two separate writes.
+const fakeBetaPasswordScript = `#!/bin/sh
+installDir=$(sed -n 's/^force_install_dir //p' "$2")
+mkdir -p "$installDir"
+cp "$2" "$installDir/script"
+printf "Error! App '233780' state is 0x6 after update job (password hun"
+printf "ter2 is invalid).\n"
+exit 8
+`
+
+func TestExec_BetaPasswordAndPlatform(t *testing.T) {
+	installDir := t.TempDir()
+	var events []steamcmd.Event
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		InstallDir: installDir,
+		DownloadGames: []steamcmd.DownloadGameOpts{{
+			Id:           233780,
+			BetaBranch:   "private",
+			BetaPassword: "hunter2",
+			Name:         "Arma3",
+			Platform:     steamcmd.PlatformWindows,
+		}},
+		SteamCmdPath: writeFakeSteamcmd(t, fakeBetaPasswordScript),
+		OnEvent: func(event steamcmd.Event) {
+			events = append(events, event)
+		},
+	})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+
+	script, err := os.ReadFile(filepath.Join(installDir, "Arma3/script"))
+	assert.NoError(t, err)
+	assert.Equal(t, `@ShutdownOnFailedCommand 1
+@sSteamCmdForcePlatformType windows
+force_install_dir `+filepath.Join(installDir, "Arma3")+`
+login anonymous
+app_update 233780 -beta private -betapassword hunter2
+quit
+`, string(script))
+
+	assert.Equal(t, []steamcmd.Event{
+		steamcmd.AppUpdateFailedEvent{
+			AppId:  233780,
+			Reason: "state is 0x6 after update job (password <redacted> is invalid)",
+		},
+	}, events)
+}
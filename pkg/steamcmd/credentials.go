@@ -0,0 +1,118 @@
+package steamcmd
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+)
+
+// CredentialsCachedError is returned when steamcmd still has a cached login for the user after
+// logging out.
+type CredentialsCachedError struct {
+	Username string
+	// The config.vdf containing the cached login.
+	Path string
+	// The section of config.vdf in which the login was found, ConnectCache or Accounts.
+	Section string
+}
+
+func (e *CredentialsCachedError) Error() string {
+	return fmt.Sprintf(
+		"the login of %s is still cached in the %s section of %s",
+		e.Username,
+		e.Section,
+		e.Path,
+	)
+}
+
+// DefaultConfigPaths returns the locations where steamcmd commonly stores its config.vdf.
+func DefaultConfigPaths() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	return []string{
+		filepath.Join(home, "Steam/config/config.vdf"),
+		filepath.Join(home, ".steam/steam/config/config.vdf"),
+		filepath.Join(home, ".steam/steamcmd/config/config.vdf"),
+		filepath.Join(home, ".local/share/Steam/config/config.vdf"),
+	}
+}
+
+// VerifyLoggedOut checks the given config.vdf files for a cached login of the user.
+// A login is cached if the ConnectCache section contains a token for the user, or if the
+// Accounts section remembers the password of the user.
+// Files that do not exist are skipped. If a login is cached, a [CredentialsCachedError] is
+// returned.
+func VerifyLoggedOut(username string, configPaths ...string) error {
+	var resultErr error
+	for _, configPath := range configPaths {
+		err := verifyLoggedOut(username, configPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			resultErr = errors.Join(resultErr, err)
+		}
+	}
+
+	return resultErr
+}
+
+func verifyLoggedOut(username string, configPath string) error {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	config, err := vdf.Parse(f)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", configPath, err)
+	}
+
+	steam, ok := config.LookupObject("InstallConfigStore", "Software", "Valve", "Steam")
+	if !ok {
+		return nil
+	}
+
+	connectCache, _ := steam.LookupObject("ConnectCache")
+	for _, pair := range connectCache {
+		if isConnectCacheKey(pair.Key, username) {
+			return &CredentialsCachedError{
+				Username: username,
+				Path:     configPath,
+				Section:  "ConnectCache",
+			}
+		}
+	}
+
+	rememberPassword, _ := steam.LookupString("Accounts", username, "RememberPassword")
+	if rememberPassword == "1" {
+		return &CredentialsCachedError{
+			Username: username,
+			Path:     configPath,
+			Section:  "Accounts",
+		}
+	}
+
+	return nil
+}
+
+// isConnectCacheKey returns true if the ConnectCache key belongs to the user.
+// The keys are the hexadecimal CRC32 of the username followed by 1.
+func isConnectCacheKey(key string, username string) bool {
+	for _, name := range []string{username, strings.ToLower(username)} {
+		sum := crc32.ChecksumIEEE([]byte(name))
+		if strings.EqualFold(key, fmt.Sprintf("%x1", sum)) ||
+			strings.EqualFold(key, fmt.Sprintf("%08x1", sum)) {
+			return true
+		}
+	}
+	return false
+}
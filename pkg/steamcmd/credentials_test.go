@@ -0,0 +1,29 @@
+package steamcmd_test
+
+import (
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLoggedOut(t *testing.T) {
+	err := steamcmd.VerifyLoggedOut(
+		"username",
+		"testdata/config_logged_out.vdf",
+		"testdata/does_not_exist.vdf",
+	)
+	assert.NoError(t, err)
+
+	err = steamcmd.VerifyLoggedOut("other", "testdata/config_cached.vdf")
+	assert.NoError(t, err)
+
+	err = steamcmd.VerifyLoggedOut("username", "testdata/config_cached.vdf")
+	var cachedErr *steamcmd.CredentialsCachedError
+	assert.ErrorAs(t, err, &cachedErr)
+	assert.Equal(t, &steamcmd.CredentialsCachedError{
+		Username: "username",
+		Path:     "testdata/config_cached.vdf",
+		Section:  "ConnectCache",
+	}, cachedErr)
+}
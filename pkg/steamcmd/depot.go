@@ -0,0 +1,73 @@
+package steamcmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// DepotManifest identifies a specific version of a depot.
+type DepotManifest struct {
+	DepotId    int
+	ManifestId uint64
+}
+
+// DepotContentDir returns the directory in which download_depot places the content of the depot.
+func DepotContentDir(installDir string, appId int, depotId int) string {
+	return filepath.Join(
+		installDir,
+		"steamapps/content",
+		"app_"+strconv.Itoa(appId),
+		"depot_"+strconv.Itoa(depotId),
+	)
+}
+
+// installDepots moves the content downloaded by download_depot into the install dir of the game,
+// overwriting the files that are already present.
+func installDepots(installDir string, game DownloadGameOpts) error {
+	for _, depot := range game.Depots {
+		contentDir := DepotContentDir(installDir, game.Id, depot.DepotId)
+		_, err := os.Stat(contentDir)
+		if err != nil {
+			return fmt.Errorf(
+				"depot %d of %s was not downloaded: %w",
+				depot.DepotId,
+				game.Name,
+				err,
+			)
+		}
+
+		err = mergeDir(contentDir, installDir)
+		if err != nil {
+			return fmt.Errorf("error installing depot %d of %s: %w", depot.DepotId, game.Name, err)
+		}
+		err = os.RemoveAll(contentDir)
+		if err != nil {
+			return fmt.Errorf("error removing downloaded depot %d: %w", depot.DepotId, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeDir moves the files in src to the same relative path in dst. Existing files are replaced.
+func mergeDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		return os.Rename(path, target)
+	})
+}
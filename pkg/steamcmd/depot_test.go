@@ -0,0 +1,67 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDepotScript places a file for every download_depot command in the location used by
+// steamcmd. The file contains the manifest ID.
+const fakeDepotScript = `#!/bin/sh
+installDir=$(sed -n 's/^force_install_dir //p' "$2")
+sed -n 's/^download_depot //p' "$2" | while read app depot manifest; do
+	dir="$installDir/steamapps/content/app_$app/depot_$depot/sub"
+	mkdir -p "$dir"
+	echo "$manifest" > "$dir/depot_$depot.txt"
+done
+`
+
+func TestExec_Depots(t *testing.T) {
+	installDir := t.TempDir()
+	gameDir := filepath.Join(installDir, "Arma3")
+	assert.NoError(t, os.MkdirAll(filepath.Join(gameDir, "sub"), 0755))
+	oldFile := filepath.Join(gameDir, "sub/depot_233781.txt")
+	assert.NoError(t, os.WriteFile(oldFile, []byte("old"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(gameDir, "keep.txt"), []byte("keep"), 0644))
+
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		InstallDir: installDir,
+		DownloadGames: []steamcmd.DownloadGameOpts{{
+			Id:   233780,
+			Name: "Arma3",
+			Depots: []steamcmd.DepotManifest{
+				{DepotId: 233781, ManifestId: 6183564618733453744},
+				{DepotId: 233782, ManifestId: 1893426745836459121},
+			},
+		}},
+		SteamCmdPath: writeFakeSteamcmd(t, fakeDepotScript),
+	})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(oldFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "6183564618733453744\n", string(content))
+	assert.FileExists(t, filepath.Join(gameDir, "sub/depot_233782.txt"))
+	assert.FileExists(t, filepath.Join(gameDir, "keep.txt"))
+	assert.NoDirExists(t, steamcmd.DepotContentDir(gameDir, 233780, 233781))
+}
+
+func TestExec_DepotNotDownloaded(t *testing.T) {
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		InstallDir: t.TempDir(),
+		DownloadGames: []steamcmd.DownloadGameOpts{{
+			Id:     233780,
+			Name:   "Arma3",
+			Depots: []steamcmd.DepotManifest{{DepotId: 233781, ManifestId: 1}},
+		}},
+		SteamCmdPath: writeFakeSteamcmd(t, "#!/bin/sh\n"),
+	})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFakeSteamcmd writes the shell script to a temporary file and returns its path so that it
+// can be used as steamcmd.
+func writeFakeSteamcmd(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "steamcmd")
+	assert.NoError(t, os.WriteFile(path, []byte(script), 0700))
+	return path
+}
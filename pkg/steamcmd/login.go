@@ -0,0 +1,202 @@
+package steamcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrConfirmationTimeout is returned when the login has not been confirmed in the Steam Mobile
+// app in time.
+var ErrConfirmationTimeout = errors.New(
+	"timed out waiting for the login to be confirmed in the Steam Mobile app",
+)
+
+const DefaultConfirmationTimeout = 2 * time.Minute
+
+// The amount of times a prompt is answered before giving up. Prevents looping on wrong
+// credentials.
+const maxPromptAnswers = 3
+
+var (
+	passwordPrompts       = []string{"password:"}
+	steamGuardCodePrompts = []string{"Steam Guard code:", "Two-factor code:"}
+)
+
+const confirmationPrompt = "Please confirm the login in the Steam Mobile app"
+
+// loginResponder watches the output of steamcmd for login prompts and answers them using the
+// authenticator.
+// Prompts are not terminated by a newline, so they are detected in the unterminated remainder
+// of the output.
+type loginResponder struct {
+	ctx           context.Context
+	authenticator Authenticator
+	username      string
+	answer        io.Writer
+	// Receives a newline after each answer, as a terminal would echo when pressing enter.
+	echo                io.Writer
+	confirmationTimeout time.Duration
+	// Called when the login cannot proceed. Stops steamcmd.
+	abort func(cause error)
+
+	line              []byte
+	answered          map[string]int
+	mu                sync.Mutex
+	confirmationTimer *time.Timer
+}
+
+func (l *loginResponder) Write(b []byte) (int, error) {
+	l.line = append(l.line, b...)
+	if i := bytes.LastIndexAny(l.line, "\r\n"); i >= 0 {
+		for _, line := range strings.FieldsFunc(string(l.line[:i]), isNewline) {
+			if strings.Contains(line, confirmationPrompt) {
+				l.startConfirmationTimer()
+			}
+		}
+		l.line = l.line[i+1:]
+	}
+
+	pending := strings.TrimSpace(string(l.line))
+	switch {
+	case hasAnySuffix(pending, passwordPrompts):
+		l.respond("password", l.authenticator.Password)
+	case hasAnySuffix(pending, steamGuardCodePrompts):
+		l.respond("Steam Guard code", l.authenticator.SteamGuardCode)
+	}
+
+	return len(b), nil
+}
+
+func (l *loginResponder) respond(
+	name string,
+	get func(ctx context.Context, username string) (string, error),
+) {
+	l.line = l.line[:0]
+	if l.answered == nil {
+		l.answered = make(map[string]int)
+	}
+	l.answered[name]++
+	if l.answered[name] > maxPromptAnswers {
+		l.abort(fmt.Errorf("steamcmd asked for the %s %d times, giving up", name, maxPromptAnswers))
+		return
+	}
+
+	answer, err := get(l.ctx, l.username)
+	if err != nil {
+		l.abort(fmt.Errorf("error getting %s: %w", name, err))
+		return
+	}
+
+	_, err = io.WriteString(l.answer, answer+"\n")
+	if err != nil {
+		l.abort(fmt.Errorf("error sending %s to steamcmd: %w", name, err))
+		return
+	}
+	_, _ = io.WriteString(l.echo, "\n")
+}
+
+// handleEvent stops waiting for the confirmation once the login completes.
+func (l *loginResponder) handleEvent(event Event) {
+	e, ok := event.(LoginEvent)
+	if !ok || (e.State != LoginStateOk && e.State != LoginStateFailed) {
+		return
+	}
+	l.stop()
+}
+
+func (l *loginResponder) startConfirmationTimer() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.confirmationTimer != nil {
+		return
+	}
+	log.Printf(
+		"Waiting up to %s for the login to be confirmed in the Steam Mobile app",
+		l.confirmationTimeout,
+	)
+	l.confirmationTimer = time.AfterFunc(l.confirmationTimeout, func() {
+		l.abort(ErrConfirmationTimeout)
+	})
+}
+
+func (l *loginResponder) stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.confirmationTimer != nil {
+		l.confirmationTimer.Stop()
+	}
+}
+
+// runWithAuthenticator runs steamcmd in a pseudo-terminal so that its login prompts can be
+// answered by the authenticator. The output is copied to stdout.
+func (r *runner) runWithAuthenticator(
+	ctx context.Context,
+	cmd *exec.Cmd,
+	username string,
+	secrets []string,
+	watchdog io.Writer,
+	abort context.CancelCauseFunc,
+) error {
+	timeout := r.confirmationTimeout
+	if timeout <= 0 {
+		timeout = DefaultConfirmationTimeout
+	}
+	responder := &loginResponder{
+		ctx:                 ctx,
+		authenticator:       r.authenticator,
+		username:            username,
+		confirmationTimeout: timeout,
+		abort:               abort,
+	}
+	parser := NewOutputParser(func(event Event) {
+		responder.handleEvent(event)
+		if r.onEvent != nil {
+			r.onEvent(event)
+		}
+	})
+
+	ptmx, err := startWithPty(cmd)
+	if err != nil {
+		return fmt.Errorf("error starting steamcmd: %w", err)
+	}
+	defer ptmx.Close()
+	responder.answer = ptmx
+	redactor := newRedactingWriter(io.MultiWriter(r.stdout(), parser), secrets)
+	responder.echo = redactor
+
+	copyDone := make(chan struct{})
+	go func() {
+		defer close(copyDone)
+		// Reading fails once steamcmd exits and closes the pseudo-terminal.
+		_, _ = io.Copy(io.MultiWriter(redactor, responder, watchdog), ptmx)
+	}()
+
+	err = cmd.Wait()
+	<-copyDone
+	_ = redactor.Flush()
+	parser.Flush()
+	responder.stop()
+
+	return err
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package steamcmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLoginScript mimics the steamcmd login with an email code prompt. It fails unless the
+// expected credentials are entered.
+const fakeLoginScript = `#!/bin/sh
+echo "Steam>login username"
+echo "Cached credentials not found."
+printf "\npassword: "
+read password
+[ "$password" = "hunter2" ] || { echo "FAILED (Invalid Password)"; exit 5; }
+echo "Proceeding with login using username/password."
+echo "Please check your email for the message from Steam, and enter the Steam Guard"
+printf "Steam Guard code:"
+read code
+[ "$code" = "AB12C" ] || { echo "FAILED (Invalid Login Auth Code)"; exit 5; }
+echo "Logging in user 'username' [U:1:0] to Steam Public...OK"
+`
+
+const fakeConfirmationScript = `#!/bin/sh
+echo "Logging in user 'username' [U:1:0] to Steam Public...This account is protected by a Steam Guard mobile authenticator."
+echo "Please confirm the login in the Steam Mobile app on your phone."
+while true; do
+	echo "Waiting for confirmation..."
+	sleep 0.1
+done
+`
+
+type staticAuthenticator struct {
+	password string
+	code     string
+}
+
+func (a staticAuthenticator) Password(context.Context, string) (string, error) {
+	return a.password, nil
+}
+
+func (a staticAuthenticator) SteamGuardCode(context.Context, string) (string, error) {
+	return a.code, nil
+}
+
+func TestExec_Authenticator(t *testing.T) {
+	var events []steamcmd.Event
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		LoginUsername: "username",
+		InstallDir:    t.TempDir(),
+		DownloadGames: []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		SteamCmdPath:  writeFakeSteamcmd(t, fakeLoginScript),
+		Authenticator: staticAuthenticator{password: "hunter2", code: "AB12C"},
+		OnEvent: func(event steamcmd.Event) {
+			events = append(events, event)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []steamcmd.Event{
+		steamcmd.LoginEvent{Username: "username", State: steamcmd.LoginStateOk},
+	}, events)
+}
+
+func TestExec_ConfirmationTimeout(t *testing.T) {
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		LoginUsername:       "username",
+		InstallDir:          t.TempDir(),
+		DownloadGames:       []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		SteamCmdPath:        writeFakeSteamcmd(t, fakeConfirmationScript),
+		Authenticator:       staticAuthenticator{},
+		ConfirmationTimeout: 500 * time.Millisecond,
+	})
+	assert.ErrorIs(t, err, steamcmd.ErrConfirmationTimeout)
+}
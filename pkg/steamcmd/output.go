@@ -0,0 +1,247 @@
+package steamcmd
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Event is emitted when a known line is encountered in the output of steamcmd.
+// It is implemented by the *Event types in this package.
+type Event interface {
+	event()
+}
+
+type LoginState int
+
+const (
+	// LoginStateLoggingIn is reported when steamcmd starts logging in the user.
+	LoginStateLoggingIn LoginState = iota
+	// LoginStateCachedCredentials is reported when steamcmd uses the stored credentials.
+	LoginStateCachedCredentials
+	LoginStateOk
+	LoginStateFailed
+)
+
+// LoginEvent reports the progress of a login.
+type LoginEvent struct {
+	// Empty when not reported by steamcmd, e.g. when using cached credentials.
+	Username string
+	State    LoginState
+	// The reason reported by steamcmd if State is LoginStateFailed.
+	Reason string
+}
+
+// AppUpdateProgressEvent is emitted for every progress line of app_update.
+type AppUpdateProgressEvent struct {
+	// The state as reported by steamcmd, e.g. downloading, verifying install.
+	State string
+	// Percentage between 0 and 100.
+	Progress float64
+	Current  uint64
+	Total    uint64
+}
+
+// AppUpdateSuccessEvent is emitted when an app has been fully installed.
+type AppUpdateSuccessEvent struct {
+	AppId int
+}
+
+// AppUpdateFailedEvent is emitted when steamcmd reports that app_update failed.
+type AppUpdateFailedEvent struct {
+	AppId  int
+	Reason string
+}
+
+// WorkshopItemDownloadedEvent is emitted when steamcmd reports that a workshop item has been
+// downloaded.
+type WorkshopItemDownloadedEvent struct {
+	WorkshopItemId uint64
+	// The directory the item was downloaded to.
+	Path string
+	Size uint64
+}
+
+// WorkshopItemFailedEvent is emitted when steamcmd reports that a workshop item could not be
+// downloaded.
+type WorkshopItemFailedEvent struct {
+	WorkshopItemId uint64
+	// The reason as reported by steamcmd, e.g. Timeout.
+	Reason string
+}
+
+// RateLimitEvent is emitted when steamcmd reports that a rate limit has been exceeded.
+type RateLimitEvent struct {
+	Line string
+}
+
+func (LoginEvent) event()                  {}
+func (AppUpdateProgressEvent) event()      {}
+func (AppUpdateSuccessEvent) event()       {}
+func (AppUpdateFailedEvent) event()        {}
+func (WorkshopItemDownloadedEvent) event() {}
+func (WorkshopItemFailedEvent) event()     {}
+func (RateLimitEvent) event()              {}
+
+var (
+	ansiEscapeRe    = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	loginUserRe     = regexp.MustCompile(`^Logging in user '([^']*)'.*?\.\.\.(.*)$`)
+	loginFailedRe   = regexp.MustCompile(`FAILED \(([^)]*)\)`)
+	appProgressRe   = regexp.MustCompile(`Update state \(0x[0-9a-fA-F]+\) ([^,]+), progress: ([0-9.]+) \((\d+) / (\d+)\)`)
+	appSuccessRe    = regexp.MustCompile(`^Success! App '(\d+)' fully installed`)
+	appFailedRe     = regexp.MustCompile(`^Error! App '(\d+)' (.*?)\.?$`)
+	itemSuccessRe   = regexp.MustCompile(`^Success\. Downloaded item (\d+) to "([^"]*)"(?: \((\d+) bytes\))?`)
+	itemFailedRe    = regexp.MustCompile(`^ERROR! Download item (\d+) failed \(([^)]*)\)`)
+	itemTimeoutRe   = regexp.MustCompile(`^ERROR! Timeout downloading item (\d+)`)
+	rateLimitMarker = "Rate Limit Exceeded"
+)
+
+// OutputParser parses the output of steamcmd that is written to it and calls the handler for
+// every recognized line.
+// Lines are terminated by either \n or \r, the latter being used by steamcmd for progress.
+type OutputParser struct {
+	handler func(Event)
+	buf     []byte
+	// Set when a login has started but its result has not been reported yet.
+	loginPending  bool
+	loginUsername string
+}
+
+func NewOutputParser(handler func(Event)) *OutputParser {
+	return &OutputParser{handler: handler}
+}
+
+func (p *OutputParser) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexAny(p.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		p.parseLine(string(p.buf[:i]))
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush parses the remaining output that has not been terminated by a newline.
+func (p *OutputParser) Flush() {
+	if len(p.buf) == 0 {
+		return
+	}
+	p.parseLine(string(p.buf))
+	p.buf = p.buf[:0]
+}
+
+func (p *OutputParser) parseLine(line string) {
+	line = strings.TrimSpace(ansiEscapeRe.ReplaceAllString(line, ""))
+	if line == "" {
+		return
+	}
+
+	if strings.Contains(line, rateLimitMarker) {
+		p.handler(RateLimitEvent{Line: line})
+	}
+
+	if m := loginUserRe.FindStringSubmatch(line); m != nil {
+		p.loginUsername = m[1]
+		p.loginPending = false
+		switch result := m[2]; {
+		case strings.HasPrefix(result, "OK"):
+			p.handler(LoginEvent{Username: p.loginUsername, State: LoginStateOk})
+		case strings.HasPrefix(result, "FAILED"):
+			p.handler(LoginEvent{
+				Username: p.loginUsername,
+				State:    LoginStateFailed,
+				Reason:   loginFailureReason(result),
+			})
+		default:
+			// The result follows later, e.g. after confirming using the Steam Guard app.
+			p.loginPending = true
+			p.handler(LoginEvent{Username: p.loginUsername, State: LoginStateLoggingIn})
+		}
+		return
+	}
+
+	if p.loginPending {
+		switch {
+		case line == "OK":
+			p.loginPending = false
+			p.handler(LoginEvent{Username: p.loginUsername, State: LoginStateOk})
+			return
+		case strings.HasPrefix(line, "FAILED") || strings.HasPrefix(line, "ERROR"):
+			p.loginPending = false
+			p.handler(LoginEvent{
+				Username: p.loginUsername,
+				State:    LoginStateFailed,
+				Reason:   loginFailureReason(line),
+			})
+			return
+		}
+	}
+
+	if line == "Logging in using cached credentials." {
+		p.handler(LoginEvent{State: LoginStateCachedCredentials})
+		return
+	}
+
+	if m := appProgressRe.FindStringSubmatch(line); m != nil {
+		progress, _ := strconv.ParseFloat(m[2], 64)
+		current, _ := strconv.ParseUint(m[3], 10, 64)
+		total, _ := strconv.ParseUint(m[4], 10, 64)
+		p.handler(AppUpdateProgressEvent{
+			State:    m[1],
+			Progress: progress,
+			Current:  current,
+			Total:    total,
+		})
+		return
+	}
+
+	if m := appSuccessRe.FindStringSubmatch(line); m != nil {
+		appId, _ := strconv.Atoi(m[1])
+		p.handler(AppUpdateSuccessEvent{AppId: appId})
+		return
+	}
+
+	if m := appFailedRe.FindStringSubmatch(line); m != nil {
+		appId, _ := strconv.Atoi(m[1])
+		p.handler(AppUpdateFailedEvent{AppId: appId, Reason: m[2]})
+		return
+	}
+
+	if m := itemSuccessRe.FindStringSubmatch(line); m != nil {
+		id, _ := strconv.ParseUint(m[1], 10, 64)
+		var size uint64
+		if m[3] != "" {
+			size, _ = strconv.ParseUint(m[3], 10, 64)
+		}
+		p.handler(WorkshopItemDownloadedEvent{
+			WorkshopItemId: id,
+			Path:           m[2],
+			Size:           size,
+		})
+		return
+	}
+
+	if m := itemFailedRe.FindStringSubmatch(line); m != nil {
+		id, _ := strconv.ParseUint(m[1], 10, 64)
+		p.handler(WorkshopItemFailedEvent{WorkshopItemId: id, Reason: m[2]})
+		return
+	}
+
+	if m := itemTimeoutRe.FindStringSubmatch(line); m != nil {
+		id, _ := strconv.ParseUint(m[1], 10, 64)
+		p.handler(WorkshopItemFailedEvent{WorkshopItemId: id, Reason: "Timeout"})
+		return
+	}
+}
+
+func loginFailureReason(s string) string {
+	if m := loginFailedRe.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package steamcmd_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputParser(t *testing.T) {
+	output := "Steam>login username\n" +
+		"Logging in user 'username' [U:1:0] to Steam Public...This account is protected by a Steam Guard mobile authenticator.\n" +
+		"Please confirm the login in the Steam Mobile app on your phone.\n" +
+		"\n" +
+		"Waiting for confirmation...\n" +
+		"OK\n" +
+		"Waiting for client config...OK\n" +
+		" Update state (0x61) downloading, progress: 45.23 (1234567 / 2730000000)\r" +
+		" Update state (0x81) verifying update, progress: 100.00 (2730000000 / 2730000000)\n" +
+		"Success! App '233780' fully installed.\n" +
+		"Error! App '233781' state is 0x202 after update job.\n" +
+		"Downloading item 463939057 ...\n" +
+		"Success. Downloaded item 463939057 to \"/games/.workshop/steamapps/workshop/content/107410/463939057\" (987654 bytes) \n" +
+		"ERROR! Download item 2950011244 failed (Timeout).\n" +
+		"ERROR! Timeout downloading item 450814997\n" +
+		"Logging in user 'other' [U:1:0] to Steam Public...FAILED (Rate Limit Exceeded)\n" +
+		"Logging in using cached credentials."
+
+	var actual []steamcmd.Event
+	parser := steamcmd.NewOutputParser(func(event steamcmd.Event) {
+		actual = append(actual, event)
+	})
+	// Write in small chunks to make sure lines split over multiple writes are handled.
+	_, err := io.CopyBuffer(parser, strings.NewReader(output), make([]byte, 7))
+	assert.NoError(t, err)
+	parser.Flush()
+
+	expected := []steamcmd.Event{
+		steamcmd.LoginEvent{Username: "username", State: steamcmd.LoginStateLoggingIn},
+		steamcmd.LoginEvent{Username: "username", State: steamcmd.LoginStateOk},
+		steamcmd.AppUpdateProgressEvent{
+			State:    "downloading",
+			Progress: 45.23,
+			Current:  1234567,
+			Total:    2730000000,
+		},
+		steamcmd.AppUpdateProgressEvent{
+			State:    "verifying update",
+			Progress: 100,
+			Current:  2730000000,
+			Total:    2730000000,
+		},
+		steamcmd.AppUpdateSuccessEvent{AppId: 233780},
+		steamcmd.AppUpdateFailedEvent{AppId: 233781, Reason: "state is 0x202 after update job"},
+		steamcmd.WorkshopItemDownloadedEvent{
+			WorkshopItemId: 463939057,
+			Path:           "/games/.workshop/steamapps/workshop/content/107410/463939057",
+			Size:           987654,
+		},
+		steamcmd.WorkshopItemFailedEvent{WorkshopItemId: 2950011244, Reason: "Timeout"},
+		steamcmd.WorkshopItemFailedEvent{WorkshopItemId: 450814997, Reason: "Timeout"},
+		steamcmd.RateLimitEvent{
+			Line: "Logging in user 'other' [U:1:0] to Steam Public...FAILED (Rate Limit Exceeded)",
+		},
+		steamcmd.LoginEvent{
+			Username: "other",
+			State:    steamcmd.LoginStateFailed,
+			Reason:   "Rate Limit Exceeded",
+		},
+		steamcmd.LoginEvent{State: steamcmd.LoginStateCachedCredentials},
+	}
+	assert.Equal(t, expected, actual)
+}
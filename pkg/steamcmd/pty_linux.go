@@ -0,0 +1,109 @@
+package steamcmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// startWithPty starts the command with its stdin, stdout, and stderr connected to a new
+// pseudo-terminal. The command becomes the session leader with the pseudo-terminal as its
+// controlling terminal.
+// The returned file is the controlling side of the pseudo-terminal and must be closed by the
+// caller after the command has exited.
+func startWithPty(cmd *exec.Cmd) (*os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error opening pseudo-terminal: %w", err)
+	}
+
+	var unlock int32
+	err = ioctl(ptmx.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock)))
+	if err != nil {
+		_ = ptmx.Close()
+		return nil, fmt.Errorf("error unlocking pseudo-terminal: %w", err)
+	}
+
+	var ptyNumber uint32
+	err = ioctl(ptmx.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&ptyNumber)))
+	if err != nil {
+		_ = ptmx.Close()
+		return nil, fmt.Errorf("error getting pseudo-terminal number: %w", err)
+	}
+
+	pts, err := os.OpenFile(
+		"/dev/pts/"+strconv.FormatUint(uint64(ptyNumber), 10),
+		os.O_RDWR|syscall.O_NOCTTY,
+		0,
+	)
+	if err != nil {
+		_ = ptmx.Close()
+		return nil, fmt.Errorf("error opening pseudo-terminal: %w", err)
+	}
+	// The child has its own copy after starting.
+	defer pts.Close()
+
+	// Answers written to the pseudo-terminal, such as passwords, must not end up in the output.
+	var termios syscall.Termios
+	err = ioctl(pts.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	if err == nil {
+		termios.Lflag &^= syscall.ECHO
+		err = ioctl(pts.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&termios)))
+	}
+	if err != nil {
+		_ = ptmx.Close()
+		return nil, fmt.Errorf("error disabling pseudo-terminal echo: %w", err)
+	}
+
+	cmd.Stdin = pts
+	cmd.Stdout = pts
+	cmd.Stderr = pts
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0
+
+	err = cmd.Start()
+	if err != nil {
+		_ = ptmx.Close()
+		return nil, err
+	}
+
+	return ptmx, nil
+}
+
+// readSecret reads a line from the terminal without echoing the input.
+// If f is not a terminal, the line is read as is.
+func readSecret(f *os.File) (string, error) {
+	var old syscall.Termios
+	err := ioctl(f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&old)))
+	if err != nil {
+		return readLine(f)
+	}
+
+	noEcho := old
+	noEcho.Lflag &^= syscall.ECHO
+	err = ioctl(f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&noEcho)))
+	if err != nil {
+		return "", fmt.Errorf("error disabling terminal echo: %w", err)
+	}
+	defer func() {
+		_ = ioctl(f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&old)))
+		_, _ = fmt.Fprintln(os.Stderr)
+	}()
+
+	return readLine(f)
+}
+
+func ioctl(fd uintptr, request uintptr, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
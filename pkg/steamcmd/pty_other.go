@@ -0,0 +1,20 @@
+//go:build !linux
+
+package steamcmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+func startWithPty(cmd *exec.Cmd) (*os.File, error) {
+	return nil, fmt.Errorf("running steamcmd in a pseudo-terminal is not supported on %s", runtime.GOOS)
+}
+
+// readSecret reads a line from f. Input is echoed since disabling it is not supported on this
+// platform.
+func readSecret(f *os.File) (string, error) {
+	return readLine(f)
+}
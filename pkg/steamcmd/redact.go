@@ -0,0 +1,77 @@
+package steamcmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// redacted replaces secrets in the output of steamcmd.
+const redacted = "<redacted>"
+
+// redact replaces all occurrences of the secrets in s.
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+// redactingWriter replaces the secrets in everything written to it before passing it on.
+// Output that could be the start of a secret is held back until it can be decided whether it is,
+// or until Flush is called.
+type redactingWriter struct {
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+func newRedactingWriter(w io.Writer, secrets []string) *redactingWriter {
+	rw := &redactingWriter{w: w}
+	for _, secret := range secrets {
+		if secret != "" {
+			rw.secrets = append(rw.secrets, []byte(secret))
+		}
+	}
+	return rw
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if len(rw.secrets) == 0 {
+		return rw.w.Write(p)
+	}
+
+	data := append(rw.pending, p...)
+	for _, secret := range rw.secrets {
+		data = bytes.ReplaceAll(data, secret, []byte(redacted))
+	}
+
+	hold := 0
+	for _, secret := range rw.secrets {
+		for n := min(len(secret)-1, len(data)); n > hold; n-- {
+			if bytes.HasSuffix(data, secret[:n]) {
+				hold = n
+				break
+			}
+		}
+	}
+
+	rw.pending = bytes.Clone(data[len(data)-hold:])
+	_, err := rw.w.Write(data[:len(data)-hold])
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the output that is held back.
+func (rw *redactingWriter) Flush() error {
+	if len(rw.pending) == 0 {
+		return nil
+	}
+	_, err := rw.w.Write(rw.pending)
+	rw.pending = nil
+	return err
+}
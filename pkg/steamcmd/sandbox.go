@@ -0,0 +1,79 @@
+package steamcmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// homeSandbox is a temporary home directory for steamcmd. Everything steamcmd stores in the home
+// directory, such as the cached credentials in config.vdf and the ssfn files, ends up in the
+// sandbox and is destroyed together with it.
+// Note that steamcmd installations that bootstrap themselves in the home directory will do so
+// for every sandbox.
+type homeSandbox struct {
+	dir string
+}
+
+func newHomeSandbox() (*homeSandbox, error) {
+	dir, err := os.MkdirTemp("", "boiler_steamcmd_home")
+	if err != nil {
+		return nil, fmt.Errorf("error creating steamcmd home sandbox: %w", err)
+	}
+
+	return &homeSandbox{dir: dir}, nil
+}
+
+// env returns the environment for steamcmd processes that use the sandbox.
+func (s *homeSandbox) env() []string {
+	return append(
+		os.Environ(),
+		"HOME="+s.dir,
+		"XDG_DATA_HOME="+filepath.Join(s.dir, ".local/share"),
+		"XDG_CONFIG_HOME="+filepath.Join(s.dir, ".config"),
+	)
+}
+
+// destroy removes the sandbox and verifies that nothing, including the login token of the
+// given user, remains on disk.
+func (s *homeSandbox) destroy(username string) error {
+	removeErr := os.RemoveAll(s.dir)
+	_, err := os.Lstat(s.dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return errors.Join(removeErr, fmt.Errorf("error verifying steamcmd home removal: %w", err))
+	}
+
+	if username == "" {
+		username = "anonymous"
+	}
+	return errors.Join(removeErr, fmt.Errorf(
+		"steamcmd home %s could not be removed, the login token of %s may remain on disk",
+		s.dir,
+		username,
+	))
+}
+
+// configPathsOutside returns the config.vdf files that steamcmd may write to regardless of the
+// sandbox. Installations such as the one from the steamcmd tarball keep their config next to the
+// binary instead of in the home directory. If configPaths is empty, [DefaultConfigPaths] are
+// included, in case the sandbox is not honored at all.
+func configPathsOutside(steamCmdPath string, configPaths []string) []string {
+	if len(configPaths) == 0 {
+		configPaths = DefaultConfigPaths()
+	}
+	paths := append([]string(nil), configPaths...)
+
+	binary, err := exec.LookPath(steamCmdPath)
+	if err != nil {
+		return paths
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	return append(paths, filepath.Join(filepath.Dir(binary), "config/config.vdf"))
+}
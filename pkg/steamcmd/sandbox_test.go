@@ -0,0 +1,65 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCredentialScript stores a fake login token in the home directory and reports the home
+// directory in the install dir, which is the argument of force_install_dir in the runscript.
+const fakeCredentialScript = `#!/bin/sh
+installDir=$(sed -n 's/^force_install_dir //p' "$2")
+mkdir -p "$HOME/Steam/config"
+echo '"InstallConfigStore" { "Software" { "Valve" { "Steam" { "ConnectCache" { "a1b2c3d41" "token" } } } } }' > "$HOME/Steam/config/config.vdf"
+mkdir -p "$installDir"
+echo "$HOME" > "$installDir/home"
+`
+
+func TestExec_IsolatedHome(t *testing.T) {
+	installDir := t.TempDir()
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		LoginUsername: "username",
+		InstallDir:    installDir,
+		DownloadGames: []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		IsolatedHome:  true,
+		SteamCmdPath:  writeFakeSteamcmd(t, fakeCredentialScript),
+	})
+	assert.NoError(t, err)
+
+	home, err := os.ReadFile(filepath.Join(installDir, "Arma3/home"))
+	assert.NoError(t, err)
+	homeDir := strings.TrimSpace(string(home))
+	assert.NotEqual(t, os.Getenv("HOME"), homeDir)
+	assert.NoDirExists(t, homeDir)
+}
+
+func TestExec_IsolatedHome_ConfigNextToBinary(t *testing.T) {
+	steamCmdPath := writeFakeSteamcmd(t, `#!/bin/sh
+mkdir -p "$(dirname "$0")/config"
+echo '"InstallConfigStore" { "Software" { "Valve" { "Steam" { "Accounts" { "username" { "RememberPassword" "1" } } } } } }' > "$(dirname "$0")/config/config.vdf"
+`)
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		LoginUsername: "username",
+		InstallDir:    t.TempDir(),
+		DownloadGames: []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		IsolatedHome:  true,
+		ConfigPaths:   []string{filepath.Join(t.TempDir(), "config.vdf")},
+		SteamCmdPath:  steamCmdPath,
+	})
+
+	var cachedErr *steamcmd.CredentialsCachedError
+	if !errors.As(err, &cachedErr) {
+		t.Fatalf("expected a CredentialsCachedError, got %v", err)
+	}
+	assert.Equal(t, filepath.Join(filepath.Dir(steamCmdPath), "config/config.vdf"), cachedErr.Path)
+	assert.Equal(t, "username", cachedErr.Username)
+}
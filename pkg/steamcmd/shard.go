@@ -0,0 +1,284 @@
+package steamcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+)
+
+// workshopShard is a steamcmd instance that downloads a share of the workshop batches to its own
+// staging directory.
+type workshopShard struct {
+	stagingDir string
+	items      []DownloadWorkshopItemOpts
+	runs       []runOpts
+}
+
+// ShardStagingDir returns the directory that the shard with the given number, starting at 1,
+// downloads workshop items to before they are moved into workshopInstallDir.
+func ShardStagingDir(workshopInstallDir string, shard int) string {
+	return filepath.Join(workshopInstallDir, ".shard_"+strconv.Itoa(shard))
+}
+
+// workshopShards distributes the workshop items over [Opts.WorkshopParallelism] shards, each of
+// which downloads its items in batches. Returns nil if the workshop items are not downloaded in
+// parallel, e.g. because there is only a single item.
+func workshopShards(opts Opts) []workshopShard {
+	shardCount := min(opts.WorkshopParallelism, len(opts.DownloadWorkshopItems))
+	if shardCount <= 1 {
+		return nil
+	}
+
+	shards := make([]workshopShard, shardCount)
+	for i, item := range opts.DownloadWorkshopItems {
+		shard := &shards[i%len(shards)]
+		shard.items = append(shard.items, item)
+	}
+
+	batchesPerShard := make([][][]DownloadWorkshopItemOpts, len(shards))
+	totalBatches := 0
+	for i, shard := range shards {
+		batchesPerShard[i] = workshopBatches(
+			shard.items,
+			opts.WorkshopBatchMaxItems,
+			opts.WorkshopBatchMaxBytes,
+		)
+		totalBatches += len(batchesPerShard[i])
+	}
+
+	batchNumber := 0
+	for i := range shards {
+		shard := &shards[i]
+		shard.stagingDir = ShardStagingDir(opts.WorkshopInstallDir, i+1)
+		for _, batch := range batchesPerShard[i] {
+			batchNumber++
+			shard.runs = append(
+				shard.runs,
+				workshopRun(opts, batch, batchNumber, totalBatches, shard.stagingDir),
+			)
+		}
+	}
+	return shards
+}
+
+// execWorkshopShards runs the shards concurrently and waits for all of them to complete.
+func execWorkshopShards(
+	ctx context.Context,
+	opts Opts,
+	shards []workshopShard,
+	onEvent func(Event),
+) error {
+	authenticator := opts.Authenticator
+	switch {
+	case authenticator == nil && opts.LoginUsername != "":
+		return errors.New(
+			"downloading workshop items in parallel requires an authenticator to log in",
+		)
+	case authenticator != nil:
+		authenticator = serializeAuthenticator(authenticator)
+	}
+
+	var installMu sync.Mutex
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := newRunner(
+				withSteamcmd(opts.SteamCmdPath),
+				withIsolatedHome(true),
+				withAuthenticator(authenticator, opts.ConfirmationTimeout),
+				withTimeouts(opts.RunTimeout, opts.InactivityTimeout),
+				withEventHandler(onEvent),
+			)
+			errs[i] = shard.exec(ctx, r, opts.WorkshopInstallDir, &installMu)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// exec downloads the workshop items of the shard and moves the ones that were downloaded into
+// workshopInstallDir, even if steamcmd failed. installMu guards the workshop manifests.
+func (s workshopShard) exec(
+	ctx context.Context,
+	r *runner,
+	workshopInstallDir string,
+	installMu *sync.Mutex,
+) error {
+	// Items left behind by an earlier, interrupted download are not trusted.
+	err := os.RemoveAll(s.stagingDir)
+	if err != nil {
+		return fmt.Errorf("error clearing staging directory: %w", err)
+	}
+	for _, run := range s.runs {
+		err := r.Add(run)
+		if err != nil {
+			return err
+		}
+	}
+
+	runErr := r.Run(ctx)
+
+	installMu.Lock()
+	defer installMu.Unlock()
+	err = installStagedWorkshopItems(s.stagingDir, workshopInstallDir, s.items)
+	if err != nil {
+		return errors.Join(runErr, err)
+	}
+	err = os.RemoveAll(s.stagingDir)
+	if err != nil {
+		return errors.Join(runErr, fmt.Errorf("error removing staging directory: %w", err))
+	}
+	return runErr
+}
+
+// installStagedWorkshopItems moves the workshop items that were downloaded to stagingDir into
+// workshopInstallDir, replacing the previous content, and records them in the
+// appworkshop_<appid>.acf manifests of workshopInstallDir.
+// Items that are not in the manifest of the staging dir are skipped.
+func installStagedWorkshopItems(
+	stagingDir string,
+	workshopInstallDir string,
+	items []DownloadWorkshopItemOpts,
+) error {
+	itemsByGame := make(map[int][]uint64)
+	for _, item := range items {
+		itemsByGame[item.GameId] = append(itemsByGame[item.GameId], item.WorkshopItemId)
+	}
+
+	for gameId, itemIds := range itemsByGame {
+		staged, err := readWorkshopManifest(stagingDir, gameId)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// None of the items of this game were downloaded.
+			continue
+		case err != nil:
+			return err
+		}
+
+		manifest, err := readWorkshopManifest(workshopInstallDir, gameId)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			manifest = vdf.Object{{Key: "appid", String: strconv.Itoa(gameId)}}
+		case err != nil:
+			return err
+		}
+
+		for _, id := range itemIds {
+			key := strconv.FormatUint(id, 10)
+			installed, ok := staged.Lookup("WorkshopItemsInstalled", key)
+			if !ok {
+				continue
+			}
+
+			target := WorkshopItemContentDir(workshopInstallDir, gameId, id)
+			err := os.RemoveAll(target)
+			if err != nil {
+				return fmt.Errorf("error removing previous content of workshop item %d: %w", id, err)
+			}
+			err = os.MkdirAll(filepath.Dir(target), 0755)
+			if err != nil {
+				return err
+			}
+			err = os.Rename(WorkshopItemContentDir(stagingDir, gameId, id), target)
+			if err != nil {
+				return fmt.Errorf("error moving workshop item %d: %w", id, err)
+			}
+
+			manifest = setWorkshopManifestEntry(manifest, "WorkshopItemsInstalled", installed)
+			if details, ok := staged.Lookup("WorkshopItemDetails", key); ok {
+				manifest = setWorkshopManifestEntry(manifest, "WorkshopItemDetails", details)
+			}
+		}
+
+		err = writeWorkshopManifest(workshopInstallDir, gameId, manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setWorkshopManifestEntry sets the entry of a workshop item in a section of the AppWorkshop
+// object, such as WorkshopItemsInstalled.
+func setWorkshopManifestEntry(appWorkshop vdf.Object, section string, entry vdf.Pair) vdf.Object {
+	sectionObject, ok := appWorkshop.LookupObject(section)
+	if !ok {
+		sectionObject = vdf.Object{}
+	}
+	return appWorkshop.Set(vdf.Pair{Key: section, Object: sectionObject.Set(entry)})
+}
+
+// writeWorkshopManifest replaces the appworkshop_<appid>.acf manifest.
+func writeWorkshopManifest(workshopInstallDir string, gameId int, appWorkshop vdf.Object) error {
+	manifestPath := workshopManifestPath(workshopInstallDir, gameId)
+	err := os.MkdirAll(filepath.Dir(manifestPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(manifestPath), ".appworkshop")
+	if err != nil {
+		return fmt.Errorf("error creating workshop manifest: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	_, err = vdf.Object{{Key: "AppWorkshop", Object: appWorkshop}}.WriteTo(temp)
+	if err != nil {
+		return fmt.Errorf("error writing workshop manifest: %w", err)
+	}
+	err = temp.Close()
+	if err != nil {
+		return fmt.Errorf("error writing workshop manifest: %w", err)
+	}
+	return os.Rename(temp.Name(), manifestPath)
+}
+
+// serializeAuthenticator returns an Authenticator that answers one prompt at a time, so that
+// concurrent steamcmd instances do not prompt the user simultaneously.
+func serializeAuthenticator(authenticator Authenticator) Authenticator {
+	serial := serialAuthenticator{mu: &sync.Mutex{}, authenticator: authenticator}
+	if generator, ok := authenticator.(SteamGuardCodeGenerator); ok {
+		return serialGeneratingAuthenticator{serialAuthenticator: serial, generator: generator}
+	}
+	return serial
+}
+
+type serialAuthenticator struct {
+	mu            *sync.Mutex
+	authenticator Authenticator
+}
+
+func (a serialAuthenticator) Password(ctx context.Context, username string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.authenticator.Password(ctx, username)
+}
+
+func (a serialAuthenticator) SteamGuardCode(ctx context.Context, username string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.authenticator.SteamGuardCode(ctx, username)
+}
+
+type serialGeneratingAuthenticator struct {
+	serialAuthenticator
+	generator SteamGuardCodeGenerator
+}
+
+func (a serialGeneratingAuthenticator) GenerateSteamGuardCode() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.generator.GenerateSteamGuardCode()
+}
@@ -0,0 +1,178 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeShardSteamcmd downloads the workshop items in the runscript to its force_install_dir once
+// the other shard has started as well.
+const fakeShardSteamcmd = `#!/bin/sh
+dir=$(sed -n 's/^force_install_dir //p' "$2")
+touch "$dir.started"
+for _ in $(seq 50); do
+	[ "$(ls -a "$(dirname "$dir")" | grep -c '\.started$')" -ge 2 ] && break
+	sleep 0.1
+done
+mkdir -p "$dir/steamapps/workshop"
+ids=$(sed -n 's/^workshop_download_item 107410 //p' "$2")
+# steamcmd keeps the items of earlier runs in the manifest.
+echo $ids >> "$dir/ids"
+{
+	echo '"AppWorkshop" { "WorkshopItemsInstalled" {'
+	for id in $(cat "$dir/ids"); do
+		echo "\"$id\" { \"size\" \"10\" }"
+	done
+	echo '} "WorkshopItemDetails" {'
+	for id in $(cat "$dir/ids"); do
+		echo "\"$id\" { \"timeupdated\" \"1\" }"
+	done
+	echo '} }'
+} > "$dir/steamapps/workshop/appworkshop_107410.acf"
+for id in $ids; do
+	mkdir -p "$dir/steamapps/workshop/content/107410/$id"
+	echo "$HOME" > "$dir/steamapps/workshop/content/107410/$id/home"
+	echo "Success. Downloaded item $id to \"$dir/steamapps/workshop/content/107410/$id\" (10 bytes)"
+done
+`
+
+func TestExec_WorkshopParallelism(t *testing.T) {
+	dir := t.TempDir()
+	// An item that was installed before is kept in the manifest.
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "steamapps/workshop"), 0700))
+	assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "steamapps/workshop/appworkshop_107410.acf"),
+		[]byte(`"AppWorkshop" { "appid" "107410" "WorkshopItemsInstalled" { "9" { "size" "1" } } }`),
+		0600,
+	))
+	assert.NoError(t, os.MkdirAll(steamcmd.WorkshopItemContentDir(dir, 107410, 1), 0700))
+	stale := filepath.Join(steamcmd.WorkshopItemContentDir(dir, 107410, 1), "stale")
+	assert.NoError(t, os.WriteFile(stale, nil, 0600))
+
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1},
+			{GameId: 107410, WorkshopItemId: 2},
+			{GameId: 107410, WorkshopItemId: 3},
+		},
+		SteamCmdPath:          writeFakeSteamcmd(t, fakeShardSteamcmd),
+		WorkshopInstallDir:    dir,
+		WorkshopBatchMaxItems: 1,
+		WorkshopParallelism:   2,
+	})
+	assert.NoError(t, err)
+
+	assert.NoFileExists(t, stale)
+	homes := make(map[string]struct{})
+	for _, id := range []uint64{1, 2, 3} {
+		home, err := os.ReadFile(
+			filepath.Join(steamcmd.WorkshopItemContentDir(dir, 107410, id), "home"),
+		)
+		assert.NoError(t, err)
+		homes[string(home)] = struct{}{}
+	}
+	assert.Len(t, homes, 2, "every shard has its own home directory")
+	assert.NoDirExists(t, steamcmd.ShardStagingDir(dir, 1))
+	assert.NoDirExists(t, steamcmd.ShardStagingDir(dir, 2))
+
+	manifest, err := os.ReadFile(filepath.Join(dir, "steamapps/workshop/appworkshop_107410.acf"))
+	assert.NoError(t, err)
+	object, err := vdf.ParseBytes(manifest)
+	assert.NoError(t, err)
+	installed, _ := object.LookupObject("AppWorkshop", "WorkshopItemsInstalled")
+	assert.Len(t, installed, 4)
+	for _, id := range []string{"1", "2", "3", "9"} {
+		_, ok := installed.Get(id)
+		assert.True(t, ok, "item %s is installed", id)
+	}
+	updated, _ := object.LookupString("AppWorkshop", "WorkshopItemDetails", "3", "timeupdated")
+	assert.Equal(t, "1", updated)
+}
+
+func TestScripts_WorkshopParallelism(t *testing.T) {
+	scripts, err := steamcmd.Scripts(steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1},
+			{GameId: 107410, WorkshopItemId: 2},
+			{GameId: 107410, WorkshopItemId: 3},
+		},
+		WorkshopInstallDir:    "/games/workshop",
+		WorkshopBatchMaxItems: 1,
+		WorkshopParallelism:   2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop/.shard_1
+login anonymous
+workshop_download_item 107410 1
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop/.shard_1
+login anonymous
+workshop_download_item 107410 3
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop/.shard_2
+login anonymous
+workshop_download_item 107410 2
+quit
+`,
+	}, scripts)
+}
+
+func TestScripts_WorkshopParallelism_DefaultBatches(t *testing.T) {
+	scripts, err := steamcmd.Scripts(steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1},
+			{GameId: 107410, WorkshopItemId: 2},
+			{GameId: 107410, WorkshopItemId: 3},
+		},
+		WorkshopInstallDir:  "/games/workshop",
+		WorkshopParallelism: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop/.shard_1
+login anonymous
+workshop_download_item 107410 1
+workshop_download_item 107410 3
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop/.shard_2
+login anonymous
+workshop_download_item 107410 2
+quit
+`,
+	}, scripts)
+
+	// A single item is not worth a shard, which starts from an empty staging directory.
+	scripts, err = steamcmd.Scripts(steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1},
+		},
+		WorkshopInstallDir:  "/games/workshop",
+		WorkshopParallelism: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+login anonymous
+workshop_download_item 107410 1
+quit
+`,
+	}, scripts)
+}
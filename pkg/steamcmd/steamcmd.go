@@ -2,6 +2,7 @@ package steamcmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,6 +11,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Opts struct {
@@ -23,79 +26,298 @@ type Opts struct {
 	SteamCmdPath string
 	// Workshop items will be installed in this directory.
 	WorkshopInstallDir string
+	// The config.vdf files that are checked for cached credentials after logging out.
+	// Defaults to [DefaultConfigPaths].
+	ConfigPaths []string
+	// If true, steamcmd is run with a temporary home directory that is destroyed when the run
+	// completes. This makes sure no credentials remain on the system, even if the run fails or is
+	// cancelled. Since the credentials are destroyed, Logout is not needed.
+	// Afterward, ConfigPaths and the config.vdf next to the steamcmd binary, which are not
+	// covered by the temporary home directory, are verified to not contain a login.
+	IsolatedHome bool
+	// If set, steamcmd is run in a pseudo-terminal and its login prompts are answered using the
+	// Authenticator. Otherwise, stdin is passed to steamcmd.
+	Authenticator Authenticator
+	// The time to wait for the login to be confirmed in the Steam Mobile app.
+	// Defaults to [DefaultConfirmationTimeout].
+	ConfirmationTimeout time.Duration
+	// If set, a steamcmd run is stopped once it takes longer than RunTimeout. steamcmd is first
+	// asked to quit and is killed if it has not done so after a grace period. A [TimeoutError] is
+	// returned.
+	RunTimeout time.Duration
+	// If set, a steamcmd run is stopped, like with RunTimeout, once it has not produced output for
+	// InactivityTimeout.
+	InactivityTimeout time.Duration
+	// If set, OnEvent is called for every event recognized in the output of steamcmd.
+	// It is called from the goroutines that copy the output of steamcmd, one call at a time.
+	OnEvent func(Event)
+	// The maximum amount of workshop items downloaded per steamcmd session. If there are more,
+	// they are split into batches that are downloaded in separate sessions. 0 is unlimited.
+	WorkshopBatchMaxItems int
+	// The maximum combined size in bytes of the workshop items downloaded per steamcmd session,
+	// see [DownloadWorkshopItemOpts.Size]. An item that exceeds it on its own is downloaded in a
+	// session of its own. 0 is unlimited.
+	WorkshopBatchMaxBytes uint64
+	// The amount of steamcmd instances that download workshop items concurrently. Each instance
+	// downloads a share of the items, in batches, to a staging directory in WorkshopInstallDir,
+	// using an isolated home directory. Once it exits, the downloaded items are moved into
+	// WorkshopInstallDir. Since the staging directories start out empty, items are downloaded
+	// in full rather than updated. If LoginUsername is set, an Authenticator is required.
+	// 0 or 1, or a single workshop item, downloads the workshop items sequentially.
+	WorkshopParallelism int
 }
 
+// Platforms that can be passed to [DownloadGameOpts.Platform].
+const (
+	PlatformWindows = "windows"
+	PlatformLinux   = "linux"
+	PlatformMacOS   = "macos"
+)
+
 type DownloadGameOpts struct {
 	Id         int
 	BetaBranch string
+	// The password of BetaBranch, if it is private. The password is redacted from the output of
+	// steamcmd.
+	BetaPassword string
+	// If set, the manifests of these depots are downloaded using download_depot instead of
+	// updating the game to the latest build of BetaBranch.
+	Depots []DepotManifest
 	// Used as the subdirectory to download the files to.
-	Name     string
+	Name string
+	// If set, the game is downloaded for this platform instead of the current one.
+	// One of PlatformWindows, PlatformLinux, or PlatformMacOS.
+	Platform string
 	Validate bool
 }
 
 type DownloadWorkshopItemOpts struct {
 	GameId         int
 	WorkshopItemId uint64
+	// The size of the workshop item in bytes, used to apply [Opts.WorkshopBatchMaxBytes].
+	// 0 if unknown.
+	Size uint64
 }
 
 // Exec executes the given steamcmd Opts.
 // Depending on the options specified, steamcmd will be run multiple times.
 // This is because games need to be installed in their own directories and force_install_dir
 // after login is discouraged.
+// Since workshop_download_item does not make steamcmd fail, every workshop item is verified after
+// the run. Items that could not be confirmed are reported using a [WorkshopDownloadError], even if
+// steamcmd failed or was not run at all.
+// Workshop items are downloaded in batches, see [Opts.WorkshopBatchMaxItems]. A batch for which
+// steamcmd fails does not stop the batches after it. The failed batches are reported in
+// [WorkshopDownloadError.Batches].
 func Exec(ctx context.Context, opts Opts) error {
+	tracker := newWorkshopTracker()
+	// Shards call the handler concurrently.
+	var eventMu sync.Mutex
+	onEvent := func(event Event) {
+		eventMu.Lock()
+		defer eventMu.Unlock()
+		tracker.handle(event)
+		if opts.OnEvent != nil {
+			opts.OnEvent(event)
+		}
+	}
 	r := newRunner(
-		withLogout(opts.Logout),
+		withLogout(opts.Logout, opts.ConfigPaths),
 		withSteamcmd(opts.SteamCmdPath),
+		withIsolatedHome(opts.IsolatedHome),
+		withAuthenticator(opts.Authenticator, opts.ConfirmationTimeout),
+		withTimeouts(opts.RunTimeout, opts.InactivityTimeout),
+		withEventHandler(onEvent),
 	)
 
+	runErr := addRuns(r, opts)
+	if runErr == nil {
+		runErr = r.Run(ctx)
+	}
+	if shards := workshopShards(opts); runErr == nil && len(shards) > 0 {
+		runErr = execWorkshopShards(ctx, opts, shards, onEvent)
+	}
+	batchErrs, runErr := splitBatchErrors(runErr)
+	for _, batchErr := range batchErrs {
+		tracker.failBatch(batchErr)
+	}
+	if runErr == nil {
+		for _, game := range opts.DownloadGames {
+			runErr = installDepots(filepath.Join(opts.InstallDir, game.Name), game)
+			if runErr != nil {
+				break
+			}
+		}
+	}
+	if len(opts.DownloadWorkshopItems) == 0 {
+		return runErr
+	}
+
+	wsErr := tracker.verify(opts.WorkshopInstallDir, opts.DownloadWorkshopItems)
+	if wsErr != nil {
+		wsErr.Batches = batchErrs
+	} else {
+		// All items have been downloaded, steamcmd failed afterward.
+		for _, batchErr := range batchErrs {
+			runErr = errors.Join(runErr, batchErr)
+		}
+	}
+	switch {
+	case runErr != nil && wsErr != nil:
+		return errors.Join(runErr, wsErr)
+	case runErr != nil:
+		return runErr
+	case wsErr != nil:
+		return wsErr
+	}
+
+	return nil
+}
+
+// addRuns adds the steamcmd runs needed to execute the Opts to the runner.
+func addRuns(r *runner, opts Opts) error {
 	var sb strings.Builder
 	for _, game := range opts.DownloadGames {
-		sb.Reset()
-		sb.WriteString("app_update ")
-		sb.WriteString(strconv.Itoa(game.Id))
-		if game.BetaBranch != "" {
-			sb.WriteString(" -beta ")
-			sb.WriteString(game.BetaBranch)
+		var commands []string
+		for _, depot := range game.Depots {
+			// The content is placed in steamapps/content of the install dir and moved into the
+			// install dir after the run.
+			commands = append(commands, fmt.Sprintf(
+				"download_depot %d %d %d",
+				game.Id,
+				depot.DepotId,
+				depot.ManifestId,
+			))
+		}
+		if len(game.Depots) == 0 {
+			sb.Reset()
+			sb.WriteString("app_update ")
+			sb.WriteString(strconv.Itoa(game.Id))
+			if game.BetaBranch != "" {
+				sb.WriteString(" -beta ")
+				sb.WriteString(game.BetaBranch)
+			}
+			if game.BetaPassword != "" {
+				sb.WriteString(" -betapassword ")
+				sb.WriteString(game.BetaPassword)
+			}
+			if game.Validate {
+				sb.WriteString(" validate")
+			}
+			commands = append(commands, sb.String())
 		}
-		if game.Validate {
-			sb.WriteString(" validate")
+		var secrets []string
+		if game.BetaPassword != "" {
+			secrets = append(secrets, game.BetaPassword)
 		}
 		err := r.Add(runOpts{
 			LoginUsername: opts.LoginUsername,
 			InstallDir:    filepath.Join(opts.InstallDir, game.Name),
-			ExtraCommands: []string{sb.String()},
+			Platform:      game.Platform,
+			ExtraCommands: commands,
+			Secrets:       secrets,
 		})
 		if err != nil {
 			return err
 		}
 	}
 
-	var workshopDownloadCommands []string
-	for _, item := range opts.DownloadWorkshopItems {
+	if len(workshopShards(opts)) > 0 {
+		// The workshop items are downloaded by the shards.
+		return nil
+	}
+	batches := workshopBatches(
+		opts.DownloadWorkshopItems,
+		opts.WorkshopBatchMaxItems,
+		opts.WorkshopBatchMaxBytes,
+	)
+	for i, batch := range batches {
+		err := r.Add(workshopRun(opts, batch, i+1, len(batches), opts.WorkshopInstallDir))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// workshopRun returns the run that downloads a batch of workshop items to installDir.
+// number is the number of the batch, starting at 1, out of total batches.
+func workshopRun(
+	opts Opts,
+	batch []DownloadWorkshopItemOpts,
+	number int,
+	total int,
+	installDir string,
+) runOpts {
+	workshopDownloadCommands := make([]string, 0, len(batch))
+	itemIds := make([]uint64, 0, len(batch))
+	for _, item := range batch {
 		workshopDownloadCommands = append(workshopDownloadCommands, fmt.Sprintf(
-			// @todo this does not error on exit, unlike download_item though download_item
-			//       downloads to ~/.steam/steamcmd/linux32\steamapps\content\app_APP_ID\item_ID
+			// This does not error on exit, unlike download_item though download_item
+			// downloads to ~/.steam/steamcmd/linux32\steamapps\content\app_APP_ID\item_ID.
+			// The downloads are verified after the run instead.
 			"workshop_download_item %d %d",
 			item.GameId,
 			item.WorkshopItemId,
 		))
+		itemIds = append(itemIds, item.WorkshopItemId)
 	}
 
-	if len(workshopDownloadCommands) > 0 {
-		err := r.Add(runOpts{
-			LoginUsername: opts.LoginUsername,
-			InstallDir:    opts.WorkshopInstallDir,
-			ExtraCommands: workshopDownloadCommands,
-		})
+	return runOpts{
+		LoginUsername:   opts.LoginUsername,
+		InstallDir:      installDir,
+		ExtraCommands:   workshopDownloadCommands,
+		ContinueOnError: true,
+		WrapError: func(err error) error {
+			return &WorkshopBatchError{
+				Batch:   number,
+				Batches: total,
+				Items:   itemIds,
+				Err:     err,
+			}
+		},
+	}
+}
+
+// Scripts returns the runscripts that Exec would pass to steamcmd for the Opts, without running
+// steamcmd. Secrets, such as beta passwords and Steam Guard codes, are redacted.
+func Scripts(opts Opts) ([]string, error) {
+	r := newRunner(withAuthenticator(opts.Authenticator, opts.ConfirmationTimeout))
+	err := addRuns(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, shard := range workshopShards(opts) {
+		r.todo = append(r.todo, shard.runs...)
+	}
+
+	scripts := make([]string, 0, len(r.todo))
+	for _, run := range r.todo {
+		var steamGuardCode string
+		if _, ok := r.authenticator.(SteamGuardCodeGenerator); ok && run.LoginUsername != "" {
+			steamGuardCode = redacted
+		}
+		var sb strings.Builder
+		err := writeScript(&sb, run, steamGuardCode)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		scripts = append(scripts, redact(sb.String(), run.Secrets))
 	}
 
-	return r.Run(ctx)
+	return scripts, nil
 }
 
-func LogOutUser(ctx context.Context, steamCmdPath string, username string) error {
+// LogOutUser logs the user out of steamcmd and verifies that no login remains cached in the
+// given config.vdf files. If configPaths is empty, [DefaultConfigPaths] are checked.
+// If a login remains cached, a [CredentialsCachedError] is returned.
+func LogOutUser(
+	ctx context.Context,
+	steamCmdPath string,
+	username string,
+	configPaths []string,
+) error {
 	logoutTmp, err := os.CreateTemp("", "steamcmd_logout")
 	if err != nil {
 		return fmt.Errorf("error creating temp file: %w", err)
@@ -117,30 +339,65 @@ quit
 		"+runscript",
 		logoutTmp.Name(),
 	)
-	return logoutCmd.Run()
+	err = logoutCmd.Run()
+	if err != nil {
+		err = fmt.Errorf("steamcmd exited unexpectedly while attempting to logout: %w", err)
+	}
+
+	if len(configPaths) == 0 {
+		configPaths = DefaultConfigPaths()
+	}
+	return errors.Join(err, VerifyLoggedOut(username, configPaths...))
 }
 
 type runner struct {
 	todo         []runOpts
 	steamCmdPath string
+	onEvent      func(Event)
+	// If true, all runs share a home directory that is destroyed after the last run.
+	isolatedHome bool
+	sandbox      *homeSandbox
+	// If set, login prompts are answered by the authenticator.
+	authenticator       Authenticator
+	confirmationTimeout time.Duration
 	// If true, the logged-in user will be logged out at the end of the last run.
 	logout bool
+	// The config.vdf files checked for cached credentials after logging out.
+	configPaths []string
+	// If set, the output of steamcmd is additionally written to it.
+	output io.Writer
+	// The writer the output of steamcmd is printed to. If nil, os.Stdout is used.
+	console io.Writer
+	// Timeouts of every run. 0 disables the timeout.
+	runTimeout        time.Duration
+	inactivityTimeout time.Duration
 }
 
 type runOpts struct {
 	// If empty, anonymous will be used.
 	LoginUsername string
 	// If true, the logged-in user will be logged out at the end of the run.
-	Logout        bool
-	InstallDir    string
+	Logout bool
+	// If empty, force_install_dir is not used.
+	InstallDir string
+	// If set, @sSteamCmdForcePlatformType is set to this platform.
+	Platform      string
 	ExtraCommands []string
+	// Values in the script that must not appear in the output, such as passwords.
+	Secrets []string
+	// If true, the runs after this one are executed even if it fails.
+	ContinueOnError bool
+	// If set, the error of the run is replaced by the result of WrapError, unless the run was
+	// cancelled.
+	WrapError func(err error) error
 }
 
 type runnerOpt func(*runner)
 
-func withLogout(logout bool) runnerOpt {
+func withLogout(logout bool, configPaths []string) runnerOpt {
 	return func(r *runner) {
 		r.logout = logout
+		r.configPaths = configPaths
 	}
 }
 func withSteamcmd(steamcmd string) runnerOpt {
@@ -148,6 +405,38 @@ func withSteamcmd(steamcmd string) runnerOpt {
 		r.steamCmdPath = steamcmd
 	}
 }
+func withIsolatedHome(isolatedHome bool) runnerOpt {
+	return func(r *runner) {
+		r.isolatedHome = isolatedHome
+	}
+}
+func withAuthenticator(authenticator Authenticator, confirmationTimeout time.Duration) runnerOpt {
+	return func(r *runner) {
+		r.authenticator = authenticator
+		r.confirmationTimeout = confirmationTimeout
+	}
+}
+func withEventHandler(onEvent func(Event)) runnerOpt {
+	return func(r *runner) {
+		r.onEvent = onEvent
+	}
+}
+func withTimeouts(runTimeout time.Duration, inactivityTimeout time.Duration) runnerOpt {
+	return func(r *runner) {
+		r.runTimeout = runTimeout
+		r.inactivityTimeout = inactivityTimeout
+	}
+}
+func withOutput(output io.Writer) runnerOpt {
+	return func(r *runner) {
+		r.output = output
+	}
+}
+func withConsole(console io.Writer) runnerOpt {
+	return func(r *runner) {
+		r.console = console
+	}
+}
 
 func newRunner(opts ...runnerOpt) *runner {
 	r := &runner{
@@ -161,35 +450,78 @@ func newRunner(opts ...runnerOpt) *runner {
 }
 
 func (r *runner) Add(opts runOpts) error {
-	if opts.InstallDir == "" {
-		return fmt.Errorf("InstallDir not set")
-	}
-
 	r.todo = append(r.todo, opts)
 	return nil
 }
 
-func (r *runner) Run(ctx context.Context) error {
-	var resultErr error
+func (r *runner) Run(ctx context.Context) (resultErr error) {
+	if r.isolatedHome {
+		sandbox, err := newHomeSandbox()
+		if err != nil {
+			return err
+		}
+		r.sandbox = sandbox
+		defer func() {
+			r.sandbox = nil
+			var username string
+			usernames := make(map[string]struct{})
+			for _, conf := range r.todo {
+				if conf.LoginUsername != "" {
+					username = conf.LoginUsername
+					usernames[username] = struct{}{}
+				}
+			}
+			if err := sandbox.destroy(username); err != nil {
+				resultErr = errors.Join(resultErr, err)
+			}
+
+			// Not every installation stores its credentials in the home directory.
+			configPaths := configPathsOutside(r.steamCmdPath, r.configPaths)
+			for username := range usernames {
+				resultErr = errors.Join(resultErr, VerifyLoggedOut(username, configPaths...))
+			}
+		}()
+	}
+
 	logOutUsers := make(map[string]struct{})
-	for _, conf := range r.todo {
-		resultErr = r.runSingle(ctx, conf)
-		if conf.LoginUsername != "" && (r.logout || (resultErr != nil && conf.Logout)) {
+	var runErrs []error
+	for i, conf := range r.todo {
+		err := r.runSingle(ctx, i+1, conf)
+		if conf.LoginUsername != "" && (r.logout || (err != nil && conf.Logout)) {
 			logOutUsers[conf.LoginUsername] = struct{}{}
 		}
-		if resultErr != nil {
+		if err == nil {
+			continue
+		}
+		if conf.WrapError != nil && ctx.Err() == nil {
+			err = conf.WrapError(err)
+		}
+		runErrs = append(runErrs, err)
+		if !conf.ContinueOnError || ctx.Err() != nil {
 			break
 		}
 	}
+	if len(runErrs) == 1 {
+		resultErr = runErrs[0]
+	} else {
+		resultErr = errors.Join(runErrs...)
+	}
 
-	if len(logOutUsers) == 0 {
+	if len(logOutUsers) == 0 || r.sandbox != nil {
+		// The credentials in the sandbox are destroyed along with it, the config outside of the
+		// sandbox is verified when it is destroyed.
 		return resultErr
 	}
 
 	for username, _ := range logOutUsers {
-		err := LogOutUser(ctx, r.steamCmdPath, username)
-		if err != nil {
-			log.Printf("WARNING: steamcmd exited unexpectedly while attempting to logout. Your credentials may still be stored on the system. %v", err)
+		// The user is logged out even if the runs were cancelled.
+		err := LogOutUser(context.WithoutCancel(ctx), r.steamCmdPath, username, r.configPaths)
+		var cachedErr *CredentialsCachedError
+		switch {
+		case errors.As(err, &cachedErr):
+			resultErr = errors.Join(resultErr, err)
+		case err != nil:
+			log.Printf("WARNING: %v. Your credentials may still be stored on the system.", err)
 		}
 	}
 
@@ -198,16 +530,22 @@ func (r *runner) Run(ctx context.Context) error {
 
 // runSingle creates a script for steamcmd to process, writes it to a temporary file, and then
 // executes it. The runscript is removed after execution.
-// Stdin, stdout, and stderr are connected to their respective stream.
-func (r *runner) runSingle(ctx context.Context, opts runOpts) error {
+// Stdin, stdout, and stderr are connected to their respective stream. If an event handler is
+// set, stdout is additionally parsed for events.
+// If an authenticator is set, steamcmd runs in a pseudo-terminal instead, see
+// runWithAuthenticator.
+// If the run exceeds one of the timeouts, a *TimeoutError is returned.
+func (r *runner) runSingle(ctx context.Context, run int, opts runOpts) error {
 	username := opts.LoginUsername
 	if username == "" {
 		username = "anonymous"
 	}
 
-	_, err := os.Stat(filepath.Dir(opts.InstallDir))
-	if err != nil {
-		return fmt.Errorf("error checking if InstallDir's parent dir exists: %w", err)
+	if opts.InstallDir != "" {
+		_, err := os.Stat(filepath.Dir(opts.InstallDir))
+		if err != nil {
+			return fmt.Errorf("error checking if InstallDir's parent dir exists: %w", err)
+		}
 	}
 
 	temp, err := os.CreateTemp("", "steamcmd_script")
@@ -217,52 +555,131 @@ func (r *runner) runSingle(ctx context.Context, opts runOpts) error {
 	defer os.Remove(temp.Name())
 	defer temp.Close()
 
-	_, err = io.WriteString(temp, "@ShutdownOnFailedCommand 1\n")
-	if err != nil {
-		return err
-	}
-	_, err = io.WriteString(temp, "force_install_dir "+opts.InstallDir+"\n")
-	if err != nil {
-		return err
-	}
-	_, err = io.WriteString(temp, "login "+username+"\n")
-	if err != nil {
-		return err
-	}
-
-	for _, command := range opts.ExtraCommands {
-		_, err = io.WriteString(temp, command+"\n")
-		if err != nil {
-			return err
-		}
-	}
-
-	if opts.Logout {
-		_, err = io.WriteString(temp, "logout\n")
+	var steamGuardCode string
+	if generator, ok := r.authenticator.(SteamGuardCodeGenerator); ok && opts.LoginUsername != "" {
+		steamGuardCode, err = generator.GenerateSteamGuardCode()
 		if err != nil {
-			return err
+			return fmt.Errorf("error generating Steam Guard code: %w", err)
 		}
 	}
-
-	_, err = io.WriteString(temp, "quit\n")
+	err = writeScript(temp, opts, steamGuardCode)
 	if err != nil {
 		return err
 	}
 
+	runCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
 	cmd := exec.CommandContext(
-		ctx,
+		runCtx,
 		r.steamCmdPath,
 		"+runscript",
 		temp.Name(),
 	)
+	if r.sandbox != nil {
+		cmd.Env = r.sandbox.env()
+	}
+	quitGracefully(cmd)
+
+	watchdog := startWatchdog(
+		r.runTimeout,
+		r.inactivityTimeout,
+		func(inactivity bool, timeout time.Duration) {
+			abort(&TimeoutError{
+				Run:        run,
+				Commands:   redactedCommands(opts),
+				Inactivity: inactivity,
+				Timeout:    timeout,
+			})
+		},
+	)
+	defer watchdog.stop()
+
+	if r.authenticator != nil {
+		err = r.runWithAuthenticator(ctx, cmd, username, opts.Secrets, watchdog, abort)
+	} else {
+		err = r.runPassthrough(cmd, opts.Secrets, watchdog)
+	}
+	if cause := context.Cause(runCtx); ctx.Err() == nil && cause != nil {
+		return cause
+	}
+	if err != nil && ctx.Err() != nil {
+		// steamcmd exited because it was stopped, report why instead of its exit status.
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// runPassthrough runs steamcmd with stdin, stdout, and stderr connected to their respective
+// stream. If an event handler is set, stdout is additionally parsed for events.
+// Everything written by steamcmd is also written to the watchdog.
+func (r *runner) runPassthrough(cmd *exec.Cmd, secrets []string, watchdog io.Writer) error {
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, watchdog)
+
+	var parser *OutputParser
+	output := r.stdout()
+	if r.onEvent != nil {
+		parser = NewOutputParser(r.onEvent)
+		output = io.MultiWriter(output, parser)
+	}
+	redactor := newRedactingWriter(output, secrets)
+	cmd.Stdout = io.MultiWriter(redactor, watchdog)
 
-	err = cmd.Start()
+	err := cmd.Start()
 	if err != nil {
 		return fmt.Errorf("error starting steamcmd: %w", err)
 	}
 
-	return cmd.Wait()
+	err = cmd.Wait()
+	_ = redactor.Flush()
+	if parser != nil {
+		parser.Flush()
+	}
+	return err
+}
+
+// writeScript writes the runscript for the run. If steamGuardCode is set, it is passed to
+// steamcmd before logging in.
+func writeScript(w io.Writer, opts runOpts, steamGuardCode string) error {
+	username := opts.LoginUsername
+	if username == "" {
+		username = "anonymous"
+	}
+
+	lines := []string{"@ShutdownOnFailedCommand 1"}
+	if opts.Platform != "" {
+		lines = append(lines, "@sSteamCmdForcePlatformType "+opts.Platform)
+	}
+	if opts.InstallDir != "" {
+		lines = append(lines, "force_install_dir "+opts.InstallDir)
+	}
+	if steamGuardCode != "" {
+		lines = append(lines, "set_steam_guard_code "+steamGuardCode)
+	}
+	lines = append(lines, "login "+username)
+	lines = append(lines, opts.ExtraCommands...)
+	if opts.Logout {
+		lines = append(lines, "logout")
+	}
+	lines = append(lines, "quit")
+
+	for _, line := range lines {
+		_, err := io.WriteString(w, line+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stdout returns the writer that the output of steamcmd is copied to.
+func (r *runner) stdout() io.Writer {
+	console := r.console
+	if console == nil {
+		console = os.Stdout
+	}
+	if r.output != nil {
+		return io.MultiWriter(console, r.output)
+	}
+	return console
 }
@@ -0,0 +1,104 @@
+package steamcmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type generatingAuthenticator struct{}
+
+func (generatingAuthenticator) Password(context.Context, string) (string, error) {
+	return "", steamcmd.ErrCredentialUnavailable
+}
+
+func (generatingAuthenticator) SteamGuardCode(context.Context, string) (string, error) {
+	return "", steamcmd.ErrCredentialUnavailable
+}
+
+func (generatingAuthenticator) GenerateSteamGuardCode() (string, error) {
+	return "X45RP", nil
+}
+
+func TestScripts(t *testing.T) {
+	scripts, err := steamcmd.Scripts(steamcmd.Opts{
+		LoginUsername: "username",
+		InstallDir:    "/games",
+		DownloadGames: []steamcmd.DownloadGameOpts{{
+			Id:           233780,
+			BetaBranch:   "private",
+			BetaPassword: "hunter2",
+			Name:         "Arma3",
+			Validate:     true,
+		}},
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 463939057},
+		},
+		WorkshopInstallDir: "/games/workshop",
+		Authenticator:      generatingAuthenticator{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/Arma3
+set_steam_guard_code <redacted>
+login username
+app_update 233780 -beta private -betapassword <redacted> validate
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+set_steam_guard_code <redacted>
+login username
+workshop_download_item 107410 463939057
+quit
+`,
+	}, scripts)
+}
+
+func TestScripts_WorkshopBatches(t *testing.T) {
+	scripts, err := steamcmd.Scripts(steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1, Size: 100},
+			{GameId: 107410, WorkshopItemId: 2, Size: 100},
+			{GameId: 107410, WorkshopItemId: 3, Size: 500},
+			{GameId: 107410, WorkshopItemId: 4},
+			{GameId: 107410, WorkshopItemId: 5},
+			{GameId: 107410, WorkshopItemId: 6},
+		},
+		WorkshopInstallDir:    "/games/workshop",
+		WorkshopBatchMaxItems: 2,
+		WorkshopBatchMaxBytes: 300,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+login anonymous
+workshop_download_item 107410 1
+workshop_download_item 107410 2
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+login anonymous
+workshop_download_item 107410 3
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+login anonymous
+workshop_download_item 107410 4
+workshop_download_item 107410 5
+quit
+`,
+		`@ShutdownOnFailedCommand 1
+force_install_dir /games/workshop
+login anonymous
+workshop_download_item 107410 6
+quit
+`,
+	}, scripts)
+}
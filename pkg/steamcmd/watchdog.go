@@ -0,0 +1,116 @@
+package steamcmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// quitGracePeriod is the time steamcmd is given to quit after being interrupted, before it is
+// killed.
+const quitGracePeriod = 15 * time.Second
+
+// TimeoutError is returned when a steamcmd run exceeds the run timeout or does not produce output
+// within the inactivity timeout.
+type TimeoutError struct {
+	// The number of the run that timed out, starting at 1.
+	Run int
+	// The commands of the run, with secrets redacted.
+	Commands []string
+	// True if the run timed out because it did not produce output.
+	Inactivity bool
+	Timeout    time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	reason := fmt.Sprintf("did not complete within %s", e.Timeout)
+	if e.Inactivity {
+		reason = fmt.Sprintf("produced no output for %s", e.Timeout)
+	}
+	return fmt.Sprintf("steamcmd run %d (%s) %s", e.Run, describeCommands(e.Commands), reason)
+}
+
+func describeCommands(commands []string) string {
+	switch len(commands) {
+	case 0:
+		return "no commands"
+	case 1:
+		return commands[0]
+	default:
+		return fmt.Sprintf("%s and %d more commands", commands[0], len(commands)-1)
+	}
+}
+
+// watchdog calls expire once the run timeout elapses, or when nothing has been written to it
+// for the inactivity timeout. A timeout of 0 disables it.
+type watchdog struct {
+	mu                sync.Mutex
+	runTimer          *time.Timer
+	inactivityTimer   *time.Timer
+	inactivityTimeout time.Duration
+}
+
+func startWatchdog(
+	runTimeout time.Duration,
+	inactivityTimeout time.Duration,
+	expire func(inactivity bool, timeout time.Duration),
+) *watchdog {
+	w := &watchdog{inactivityTimeout: inactivityTimeout}
+	if runTimeout > 0 {
+		w.runTimer = time.AfterFunc(runTimeout, func() {
+			expire(false, runTimeout)
+		})
+	}
+	if inactivityTimeout > 0 {
+		w.inactivityTimer = time.AfterFunc(inactivityTimeout, func() {
+			expire(true, inactivityTimeout)
+		})
+	}
+	return w
+}
+
+// Write registers activity.
+func (w *watchdog) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.inactivityTimer != nil {
+		w.inactivityTimer.Reset(w.inactivityTimeout)
+	}
+	return len(p), nil
+}
+
+func (w *watchdog) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.runTimer != nil {
+		w.runTimer.Stop()
+	}
+	if w.inactivityTimer != nil {
+		w.inactivityTimer.Stop()
+		w.inactivityTimer = nil
+	}
+}
+
+// quitGracefully makes cancelling the context of the command interrupt steamcmd, allowing it to
+// quit, instead of killing it. If steamcmd has not exited after quitGracePeriod, it is killed.
+func quitGracefully(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		err := cmd.Process.Signal(os.Interrupt)
+		if err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = quitGracePeriod
+}
+
+// redactedCommands returns the commands of the run with the secrets redacted.
+func redactedCommands(opts runOpts) []string {
+	result := make([]string, 0, len(opts.ExtraCommands))
+	for _, command := range opts.ExtraCommands {
+		result = append(result, redact(command, opts.Secrets))
+	}
+	return result
+}
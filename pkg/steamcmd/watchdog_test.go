@@ -0,0 +1,70 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExec_InactivityTimeout(t *testing.T) {
+	dir := t.TempDir()
+	loggedOut := filepath.Join(dir, "logged_out")
+	steamcmdPath := writeFakeSteamcmd(t, `#!/bin/sh
+if grep -q app_update "$2"; then
+	echo "Update state (0x61) downloading"
+	exec sleep 10
+fi
+touch '`+loggedOut+`'
+`)
+
+	start := time.Now()
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		LoginUsername:     "username",
+		ConfigPaths:       []string{filepath.Join(dir, "config.vdf")},
+		DownloadGames:     []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		InactivityTimeout: 500 * time.Millisecond,
+		Logout:            true,
+		SteamCmdPath:      steamcmdPath,
+	})
+
+	var timeoutErr *steamcmd.TimeoutError
+	if assert.True(t, errors.As(err, &timeoutErr), "expected a TimeoutError, got %v", err) {
+		assert.Equal(t, 1, timeoutErr.Run)
+		assert.True(t, timeoutErr.Inactivity)
+		assert.Equal(t, []string{"app_update 233780"}, timeoutErr.Commands)
+	}
+	assert.Less(t, time.Since(start), 5*time.Second, "steamcmd was not stopped")
+	_, err = os.Stat(loggedOut)
+	assert.NoError(t, err, "the user was not logged out")
+}
+
+func TestExec_RunTimeout(t *testing.T) {
+	steamcmdPath := writeFakeSteamcmd(t, `#!/bin/sh
+while true; do
+	echo "Update state (0x61) downloading"
+	sleep 0.1
+done
+`)
+
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		DownloadGames:     []steamcmd.DownloadGameOpts{{Id: 233780, Name: "Arma3"}},
+		InactivityTimeout: 500 * time.Millisecond,
+		RunTimeout:        time.Second,
+		SteamCmdPath:      steamcmdPath,
+	})
+
+	var timeoutErr *steamcmd.TimeoutError
+	if assert.True(t, errors.As(err, &timeoutErr), "expected a TimeoutError, got %v", err) {
+		assert.Equal(t, 1, timeoutErr.Run)
+		assert.False(t, timeoutErr.Inactivity)
+		assert.Equal(t, time.Second, timeoutErr.Timeout)
+	}
+}
@@ -0,0 +1,273 @@
+package steamcmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+)
+
+// WorkshopItemFailure describes a workshop item that could not be confirmed to be downloaded.
+type WorkshopItemFailure struct {
+	GameId         int
+	WorkshopItemId uint64
+	Reason         string
+}
+
+// WorkshopDownloadError is returned by Exec when one or more of the workshop items could not be
+// confirmed to be downloaded.
+// Items that are not listed in Failures have been downloaded.
+type WorkshopDownloadError struct {
+	Failures []WorkshopItemFailure
+	// The batches for which steamcmd failed. Their items that could not be confirmed are listed
+	// in Failures.
+	Batches []*WorkshopBatchError
+}
+
+func (e *WorkshopDownloadError) Error() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "%d workshop item(s) failed to download:", len(e.Failures))
+	for _, failure := range e.Failures {
+		_, _ = fmt.Fprintf(&sb, " %d (%s),", failure.WorkshopItemId, failure.Reason)
+	}
+	result := strings.TrimSuffix(sb.String(), ",")
+	for _, batch := range e.Batches {
+		result += "; " + batch.Error()
+	}
+	return result
+}
+
+// WorkshopBatchError reports that steamcmd failed while downloading a batch of workshop items.
+type WorkshopBatchError struct {
+	// The number of the batch, starting at 1.
+	Batch int
+	// The total amount of batches.
+	Batches int
+	// The workshop items of the batch.
+	Items []uint64
+	Err   error
+}
+
+func (e *WorkshopBatchError) Error() string {
+	return fmt.Sprintf(
+		"workshop batch %d of %d (%d items) failed: %v",
+		e.Batch,
+		e.Batches,
+		len(e.Items),
+		e.Err,
+	)
+}
+
+func (e *WorkshopBatchError) Unwrap() error {
+	return e.Err
+}
+
+// splitBatchErrors separates the errors of failed workshop batches from the other errors in err.
+func splitBatchErrors(err error) ([]*WorkshopBatchError, error) {
+	switch e := err.(type) {
+	case nil:
+		return nil, nil
+	case *WorkshopBatchError:
+		return []*WorkshopBatchError{e}, nil
+	case interface{ Unwrap() []error }:
+		var batchErrs []*WorkshopBatchError
+		var rest error
+		for _, err := range e.Unwrap() {
+			batches, other := splitBatchErrors(err)
+			batchErrs = append(batchErrs, batches...)
+			if other != nil {
+				rest = errors.Join(rest, other)
+			}
+		}
+		return batchErrs, rest
+	default:
+		return nil, err
+	}
+}
+
+// workshopBatches splits the items into batches of at most maxItems items and maxBytes bytes.
+// An item that exceeds maxBytes on its own is placed in a batch of its own. A limit of 0 is
+// unlimited.
+func workshopBatches(
+	items []DownloadWorkshopItemOpts,
+	maxItems int,
+	maxBytes uint64,
+) [][]DownloadWorkshopItemOpts {
+	var batches [][]DownloadWorkshopItemOpts
+	var batch []DownloadWorkshopItemOpts
+	var batchBytes uint64
+	for _, item := range items {
+		full := maxItems > 0 && len(batch) >= maxItems ||
+			maxBytes > 0 && len(batch) > 0 && batchBytes+item.Size > maxBytes
+		if full {
+			batches = append(batches, batch)
+			batch = nil
+			batchBytes = 0
+		}
+		batch = append(batch, item)
+		batchBytes += item.Size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// workshopTracker records the outcome of workshop item downloads as reported by steamcmd.
+type workshopTracker struct {
+	downloaded map[uint64]struct{}
+	failed     map[uint64]string
+}
+
+func newWorkshopTracker() *workshopTracker {
+	return &workshopTracker{
+		downloaded: make(map[uint64]struct{}),
+		failed:     make(map[uint64]string),
+	}
+}
+
+func (t *workshopTracker) handle(event Event) {
+	switch e := event.(type) {
+	case WorkshopItemDownloadedEvent:
+		t.downloaded[e.WorkshopItemId] = struct{}{}
+		delete(t.failed, e.WorkshopItemId)
+	case WorkshopItemFailedEvent:
+		t.failed[e.WorkshopItemId] = e.Reason
+		delete(t.downloaded, e.WorkshopItemId)
+	}
+}
+
+// failBatch marks the items of the batch that steamcmd did not report on as failed.
+func (t *workshopTracker) failBatch(batchErr *WorkshopBatchError) {
+	for _, id := range batchErr.Items {
+		_, downloaded := t.downloaded[id]
+		_, failed := t.failed[id]
+		if !downloaded && !failed {
+			t.failed[id] = fmt.Sprintf(
+				"steamcmd failed in batch %d before reporting the item: %v",
+				batchErr.Batch,
+				batchErr.Err,
+			)
+		}
+	}
+}
+
+// verify confirms that every item has been downloaded by checking that steamcmd reported the
+// success, that the item is present in the appworkshop_<appid>.acf manifest, and that its content
+// directory exists.
+// Returns nil if all items have been confirmed.
+func (t *workshopTracker) verify(
+	workshopInstallDir string,
+	items []DownloadWorkshopItemOpts,
+) *WorkshopDownloadError {
+	var failures []WorkshopItemFailure
+	manifests := make(map[int]vdf.Object)
+	manifestErrs := make(map[int]error)
+
+	for _, item := range items {
+		fail := func(reason string) {
+			failures = append(failures, WorkshopItemFailure{
+				GameId:         item.GameId,
+				WorkshopItemId: item.WorkshopItemId,
+				Reason:         reason,
+			})
+		}
+
+		if reason, ok := t.failed[item.WorkshopItemId]; ok {
+			fail(reason)
+			continue
+		}
+		if _, ok := t.downloaded[item.WorkshopItemId]; !ok {
+			fail("steamcmd did not report the item as downloaded")
+			continue
+		}
+
+		installed, ok := manifests[item.GameId]
+		if !ok {
+			installed, manifestErrs[item.GameId] = readInstalledWorkshopItems(
+				workshopInstallDir,
+				item.GameId,
+			)
+			manifests[item.GameId] = installed
+		}
+		if err := manifestErrs[item.GameId]; err != nil {
+			fail(err.Error())
+			continue
+		}
+		if _, ok := installed.Get(strconv.FormatUint(item.WorkshopItemId, 10)); !ok {
+			fail(fmt.Sprintf("item is missing from appworkshop_%d.acf", item.GameId))
+			continue
+		}
+
+		_, err := os.Stat(WorkshopItemContentDir(
+			workshopInstallDir,
+			item.GameId,
+			item.WorkshopItemId,
+		))
+		if err != nil {
+			fail(fmt.Sprintf("error checking content directory: %v", err))
+			continue
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &WorkshopDownloadError{Failures: failures}
+}
+
+// WorkshopItemContentDir returns the directory in which steamcmd places the content of the
+// workshop item.
+func WorkshopItemContentDir(workshopInstallDir string, gameId int, workshopItemId uint64) string {
+	return filepath.Join(
+		workshopInstallDir,
+		"steamapps/workshop/content",
+		strconv.Itoa(gameId),
+		strconv.FormatUint(workshopItemId, 10),
+	)
+}
+
+// readInstalledWorkshopItems returns the WorkshopItemsInstalled section of the
+// appworkshop_<appid>.acf manifest.
+func readInstalledWorkshopItems(workshopInstallDir string, gameId int) (vdf.Object, error) {
+	appWorkshop, err := readWorkshopManifest(workshopInstallDir, gameId)
+	if err != nil {
+		return nil, err
+	}
+	installed, _ := appWorkshop.LookupObject("WorkshopItemsInstalled")
+	return installed, nil
+}
+
+// readWorkshopManifest returns the AppWorkshop object of the appworkshop_<appid>.acf manifest.
+func readWorkshopManifest(workshopInstallDir string, gameId int) (vdf.Object, error) {
+	manifestPath := workshopManifestPath(workshopInstallDir, gameId)
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening workshop manifest: %w", err)
+	}
+	defer f.Close()
+
+	manifest, err := vdf.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", manifestPath, err)
+	}
+
+	appWorkshop, ok := manifest.LookupObject("AppWorkshop")
+	if !ok {
+		return nil, fmt.Errorf("workshop manifest does not contain AppWorkshop")
+	}
+	return appWorkshop, nil
+}
+
+func workshopManifestPath(workshopInstallDir string, gameId int) string {
+	return filepath.Join(
+		workshopInstallDir,
+		"steamapps/workshop",
+		fmt.Sprintf("appworkshop_%d.acf", gameId),
+	)
+}
@@ -0,0 +1,50 @@
+//go:build unix
+
+package steamcmd_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamcmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExec_FailedBatchDoesNotAbortLaterBatches(t *testing.T) {
+	dir := t.TempDir()
+	contentDir := steamcmd.WorkshopItemContentDir(dir, 107410, 2)
+	steamcmdPath := writeFakeSteamcmd(t, `#!/bin/sh
+if grep -q "workshop_download_item 107410 1" "$2"; then
+	exit 8
+fi
+mkdir -p '`+contentDir+`'
+printf '"AppWorkshop"\n{\n"WorkshopItemsInstalled"\n{\n"2"\n{\n}\n}\n}\n' \
+	> '`+filepath.Join(dir, "steamapps/workshop/appworkshop_107410.acf")+`'
+echo 'Success. Downloaded item 2 to "`+contentDir+`" (10 bytes)'
+`)
+
+	err := steamcmd.Exec(context.Background(), steamcmd.Opts{
+		DownloadWorkshopItems: []steamcmd.DownloadWorkshopItemOpts{
+			{GameId: 107410, WorkshopItemId: 1},
+			{GameId: 107410, WorkshopItemId: 2},
+		},
+		SteamCmdPath:          steamcmdPath,
+		WorkshopInstallDir:    dir,
+		WorkshopBatchMaxItems: 1,
+	})
+
+	var wsErr *steamcmd.WorkshopDownloadError
+	if !assert.True(t, errors.As(err, &wsErr), "expected a WorkshopDownloadError, got %v", err) {
+		return
+	}
+	assert.Len(t, wsErr.Failures, 1)
+	assert.Equal(t, uint64(1), wsErr.Failures[0].WorkshopItemId)
+	if assert.Len(t, wsErr.Batches, 1) {
+		assert.Equal(t, 1, wsErr.Batches[0].Batch)
+		assert.Equal(t, 2, wsErr.Batches[0].Batches)
+		assert.Equal(t, []uint64{1}, wsErr.Batches[0].Items)
+	}
+	assert.ErrorContains(t, err, "workshop batch 1 of 2 (1 items) failed: exit status 8")
+}
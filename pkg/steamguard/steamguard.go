@@ -0,0 +1,55 @@
+// Package steamguard generates Steam Guard codes in the same way as the Steam mobile
+// authenticator.
+package steamguard
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The characters used in Steam Guard codes.
+const codeChars = "23456789BCDFGHJKMNPQRTVWXY"
+
+const (
+	codeLength = 5
+	// Codes are valid for this period.
+	period = 30
+)
+
+// ParseSharedSecret decodes the base64 encoded shared_secret of a Steam mobile authenticator,
+// as found in e.g. a maFile.
+func ParseSharedSecret(sharedSecret string) ([]byte, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sharedSecret))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding shared secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Code returns the Steam Guard code valid at the given time.
+// This is TOTP as defined in RFC 6238 using HMAC-SHA1, with the exception that the truncated
+// value is encoded using the Steam alphabet instead of decimal digits.
+func Code(sharedSecret []byte, t time.Time) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/period))
+
+	mac := hmac.New(sha1.New, sharedSecret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	code := make([]byte, codeLength)
+	for i := range code {
+		code[i] = codeChars[value%uint32(len(codeChars))]
+		value /= uint32(len(codeChars))
+	}
+
+	return string(code)
+}
@@ -0,0 +1,24 @@
+package steamguard_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamguard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	secret, err := steamguard.ParseSharedSecret("cnOgv/KdpLoP6Nbh0GMkXkPXALQ=")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "X45RP", steamguard.Code(secret, time.Unix(1700000000, 0)))
+	assert.Equal(t, "PW3WD", steamguard.Code(secret, time.Unix(1758384867, 0)))
+	// Codes are valid for 30 seconds.
+	assert.Equal(t, "PW3WD", steamguard.Code(secret, time.Unix(1758384845, 0)))
+}
+
+func TestParseSharedSecret_Invalid(t *testing.T) {
+	_, err := steamguard.ParseSharedSecret("not base64!")
+	assert.Error(t, err)
+}
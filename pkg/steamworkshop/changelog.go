@@ -0,0 +1,143 @@
+package steamworkshop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ChangelogEntry is a single update of a workshop item as listed on its changelog page.
+type ChangelogEntry struct {
+	// Time when the update was published.
+	Time time.Time
+	// The description of the update, empty if the author did not provide one.
+	Description string
+}
+
+// GetChangelog fetches the changelog page of the workshop item using the default client and
+// extracts the entries from it.
+func GetChangelog(ctx context.Context, id uint64) ([]ChangelogEntry, error) {
+	return defaultClient.GetChangelog(ctx, id)
+}
+
+// GetChangelog fetches the changelog page of the workshop item and extracts the entries from it.
+// Only the first page, which contains the most recent entries, is retrieved.
+func (c *Client) GetChangelog(ctx context.Context, id uint64) ([]ChangelogEntry, error) {
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "sharedfiles/filedetails/changelog",
+		method:   http.MethodGet,
+		url:      fmt.Sprintf("%s/sharedfiles/filedetails/changelog/%d", c.communityBaseUrl, id),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	return ExtractChangelogFromHtml(resp.Body)
+}
+
+var tagP = []byte("p")
+var tagBr = []byte("br")
+var changeLogClass = []byte("changeLogCtn")
+
+// ExtractChangelogFromHtml extracts the entries from a changelog page of a workshop item.
+// The entries are sorted from newest to oldest.
+func ExtractChangelogFromHtml(r io.Reader) ([]ChangelogEntry, error) {
+	/*
+		Entry:
+			time: .changeLogCtn p[id] unix timestamp
+			description: .changeLogCtn p Text, <br> separates lines
+	*/
+	z := html.NewTokenizer(r)
+	var hasAttr bool
+	var tagName []byte
+	var inChangelog bool
+	var inEntry bool
+	var result []ChangelogEntry
+	var nextEntry ChangelogEntry
+	var description strings.Builder
+	inChangelogTracker := &depthTracker{}
+
+	for {
+		tokenType := z.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				return nil, fmt.Errorf("tokenizer error: %w", z.Err())
+			}
+			slices.SortStableFunc(result, func(a, b ChangelogEntry) int {
+				return b.Time.Compare(a.Time)
+			})
+			return result, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tagName, hasAttr = z.TagName()
+			if tokenType == html.StartTagToken {
+				inChangelogTracker.Increase(tagName)
+			}
+
+			if inEntry && bytes.Equal(tagName, tagBr) {
+				description.WriteByte('\n')
+				break
+			}
+
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				if !inChangelog &&
+					bytes.Equal(key, classAttr) &&
+					slices.ContainsFunc(bytes.Fields(val), func(class []byte) bool {
+						return bytes.Equal(class, changeLogClass)
+					}) {
+					inChangelog = true
+					inChangelogTracker.Reset(slices.Clone(tagName), func() {
+						inChangelog = false
+					})
+					break
+				}
+				if inChangelog && bytes.Equal(tagName, tagP) && bytes.Equal(key, idAttr) {
+					timestamp, err := strconv.ParseInt(string(val), 10, 64)
+					if err != nil {
+						break
+					}
+					nextEntry.Time = time.Unix(timestamp, 0)
+					inEntry = true
+					break
+				}
+			}
+		case html.EndTagToken:
+			tagName, _ = z.TagName()
+			if inEntry && bytes.Equal(tagName, tagP) {
+				nextEntry.Description = cleanChangelogDescription(description.String())
+				result = append(result, nextEntry)
+				nextEntry = ChangelogEntry{}
+				description.Reset()
+				inEntry = false
+			}
+			inChangelogTracker.Decrease(tagName)
+		case html.TextToken:
+			if inEntry {
+				// Line breaks in the HTML are whitespace, only <br> separates lines.
+				description.Write(bytes.ReplaceAll(z.Text(), []byte("\n"), []byte(" ")))
+			}
+		}
+	}
+}
+
+// cleanChangelogDescription collapses the whitespace in the lines of the description, which is
+// caused by the indentation of the HTML.
+func cleanChangelogDescription(description string) string {
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
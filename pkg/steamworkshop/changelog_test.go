@@ -0,0 +1,34 @@
+package steamworkshop_test
+
+import (
+	"bytes"
+	_ "embed"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed testdata/changelog_463939057.html
+var changelogPage []byte
+
+func TestExtractChangelogFromHtml(t *testing.T) {
+	actual, err := steamworkshop.ExtractChangelogFromHtml(bytes.NewReader(changelogPage))
+	assert.NoError(t, err)
+	expected := []steamworkshop.ChangelogEntry{
+		{
+			Time:        time.Unix(1710265500, 0),
+			Description: "ACE3 v3.17.0\nAdded: Fortify tool budget & rotation\nFixed: Medical vitals desync",
+		},
+		{
+			Time:        time.Unix(1704448920, 0),
+			Description: "",
+		},
+		{
+			Time:        time.Unix(1703104200, 0),
+			Description: "ACE3 v3.16.3",
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
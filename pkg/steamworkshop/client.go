@@ -0,0 +1,127 @@
+package steamworkshop
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultApiBaseUrl is the base URL of the Steam Web API.
+	DefaultApiBaseUrl = "https://api.steampowered.com"
+	// DefaultCommunityBaseUrl is the base URL of the Steam Community website.
+	DefaultCommunityBaseUrl = "https://steamcommunity.com"
+	// DefaultRateLimit is the maximum amount of requests per second sent by a client.
+	DefaultRateLimit = 5
+	// DefaultRetries is the amount of times a request that failed transiently is retried.
+	DefaultRetries = 3
+	// DefaultRetryBackoff is the time to wait before the first retry.
+	DefaultRetryBackoff = time.Second
+)
+
+// Client retrieves the details of workshop items and collections from the Steam Web API and the
+// Steam Community website.
+type Client struct {
+	apiBaseUrl       string
+	apiKey           string
+	communityBaseUrl string
+	httpClient       *http.Client
+	rateLimit        *tokenBucket
+	retries          int
+	retryBackoff     time.Duration
+	timeout          time.Duration
+	userAgent        string
+}
+
+type ClientOpt func(c *Client)
+
+// WithHttpClient sets the HTTP client used to send the requests. Defaults to
+// [http.DefaultClient].
+func WithHttpClient(httpClient *http.Client) ClientOpt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithApiBaseUrl sets the base URL of the Steam Web API. Defaults to [DefaultApiBaseUrl].
+func WithApiBaseUrl(baseUrl string) ClientOpt {
+	return func(c *Client) {
+		c.apiBaseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
+// WithApiKey sets the Steam Web API key, which is required by some endpoints.
+// See https://steamcommunity.com/dev/apikey.
+func WithApiKey(apiKey string) ClientOpt {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// HasApiKey returns true if the client has a Steam Web API key.
+func (c *Client) HasApiKey() bool {
+	return c.apiKey != ""
+}
+
+// WithCommunityBaseUrl sets the base URL of the Steam Community website. Defaults to
+// [DefaultCommunityBaseUrl].
+func WithCommunityBaseUrl(baseUrl string) ClientOpt {
+	return func(c *Client) {
+		c.communityBaseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests. If empty, the default of the HTTP
+// client is used.
+func WithUserAgent(userAgent string) ClientOpt {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRateLimit limits the requests of the client to perSecond requests per second, with bursts
+// of up to burst requests. A perSecond of 0 or less is unlimited. Defaults to [DefaultRateLimit]
+// with bursts of as many requests.
+func WithRateLimit(perSecond float64, burst int) ClientOpt {
+	return func(c *Client) {
+		c.rateLimit = newTokenBucket(perSecond, burst)
+	}
+}
+
+// WithRetries sets the amount of times a request is retried when it fails due to a network error,
+// rate limiting (429), or a server error (5xx). The time between attempts is backoff, doubled
+// after every attempt, unless the Retry-After header of the response asks otherwise.
+// Defaults to [DefaultRetries] and [DefaultRetryBackoff].
+func WithRetries(retries int, backoff time.Duration) ClientOpt {
+	return func(c *Client) {
+		c.retries = max(retries, 0)
+		c.retryBackoff = backoff
+	}
+}
+
+// WithTimeout limits the time a request may take, including reading the response. 0 is
+// unlimited. Every attempt of a retried request gets the full time.
+func WithTimeout(timeout time.Duration) ClientOpt {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// NewClient returns a client that uses the Steam Web API and the Steam Community website, unless
+// configured otherwise.
+func NewClient(opts ...ClientOpt) *Client {
+	c := &Client{
+		apiBaseUrl:       DefaultApiBaseUrl,
+		communityBaseUrl: DefaultCommunityBaseUrl,
+		httpClient:       http.DefaultClient,
+		rateLimit:        newTokenBucket(DefaultRateLimit, DefaultRateLimit),
+		retries:          DefaultRetries,
+		retryBackoff:     DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var defaultClient = NewClient()
@@ -0,0 +1,154 @@
+package steamworkshop_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_BaseUrlsAndUserAgent(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.UserAgent())
+		switch r.URL.Path {
+		case "/api/ISteamRemoteStorage/GetPublishedFileDetails/v1/":
+			_, _ = w.Write([]byte(`{"response":{"result":1,"resultcount":1,"publishedfiledetails":[
+				{"publishedfileid":"5","result":1,"creator_app_id":107410,"file_size":"10","title":"Five"}
+			]}}`))
+		case "/community/sharedfiles/filedetails/":
+			_, _ = w.Write([]byte(`<html><body><div class="workshopItemTitle">Five</div></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := steamworkshop.NewClient(
+		steamworkshop.WithApiBaseUrl(server.URL+"/api/"),
+		steamworkshop.WithCommunityBaseUrl(server.URL+"/community"),
+		steamworkshop.WithUserAgent("boiler-test"),
+	)
+
+	details, err := client.FileDetailsApi(context.Background(), 5)
+	assert.NoError(t, err)
+	if assert.Len(t, details, 1) {
+		assert.Equal(t, "Five", details[0].Title)
+	}
+	web, err := client.GetFileDetailsWeb(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Five", web.Title)
+
+	assert.Equal(t, []string{
+		"POST /api/ISteamRemoteStorage/GetPublishedFileDetails/v1/ boiler-test",
+		"GET /community/sharedfiles/filedetails/?id=5 boiler-test",
+	}, requests)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := steamworkshop.NewClient(
+		steamworkshop.WithCommunityBaseUrl(server.URL),
+		steamworkshop.WithTimeout(100*time.Millisecond),
+		steamworkshop.WithRetries(0, 0),
+	)
+
+	start := time.Now()
+	_, err := client.GetFileDetailsWeb(context.Background(), 5)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[attempts]
+		attempts++
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`<html><body><div class="workshopItemTitle">Five</div></body></html>`))
+	}))
+	defer server.Close()
+
+	client := steamworkshop.NewClient(
+		steamworkshop.WithCommunityBaseUrl(server.URL),
+		steamworkshop.WithRetries(2, time.Millisecond),
+	)
+
+	start := time.Now()
+	web, err := client.GetFileDetailsWeb(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Five", web.Title)
+	assert.Equal(t, 3, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "Retry-After was not respected")
+}
+
+func TestClient_RequestError(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		expectedAttempts int
+	}{
+		{"client errors are not retried", http.StatusNotFound, 1},
+		{"server errors are retried", http.StatusBadGateway, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			client := steamworkshop.NewClient(
+				steamworkshop.WithApiBaseUrl(server.URL),
+				steamworkshop.WithRetries(2, time.Millisecond),
+			)
+
+			_, err := client.FileDetailsApi(context.Background(), 5)
+			var reqErr *steamworkshop.RequestError
+			if assert.True(t, errors.As(err, &reqErr), "expected a RequestError, got %v", err) {
+				assert.Equal(t, "ISteamRemoteStorage/GetPublishedFileDetails", reqErr.Endpoint)
+				assert.Equal(t, test.status, reqErr.StatusCode)
+				assert.Equal(t, test.expectedAttempts, reqErr.Attempts)
+			}
+			assert.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body></body></html>`))
+	}))
+	defer server.Close()
+
+	client := steamworkshop.NewClient(
+		steamworkshop.WithCommunityBaseUrl(server.URL),
+		steamworkshop.WithRateLimit(20, 2),
+	)
+
+	start := time.Now()
+	for range 6 {
+		_, err := client.GetFileDetailsWeb(context.Background(), 5)
+		assert.NoError(t, err)
+	}
+	// The first two requests are sent immediately, the other four every 50ms.
+	assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+}
@@ -8,7 +8,6 @@ import (
 	"net/url"
 	"slices"
 	"strconv"
-	"strings"
 
 	"github.com/go-json-experiment/json"
 )
@@ -57,32 +56,45 @@ type CollectionDetailItem struct {
 }
 
 // CollectionDetailsApi returns the details of the collection according to the
-// [GetCollectionDetails] API endpoint.
+// [GetCollectionDetails] API endpoint using the default client.
 // The response contains the details in the same order as the input.
 // The child items are sorted according to the sort order.
 //
 // [GetCollectionDetails]: https://partner.steamgames.com/doc/webapi/ISteamRemoteStorage#GetCollectionDetails
 func CollectionDetailsApi(ctx context.Context, collectionIds ...uint64) ([]CollectionDetailApi, error) {
+	return defaultClient.CollectionDetailsApi(ctx, collectionIds...)
+}
+
+// CollectionDetailsApi returns the details of the collection according to the
+// [GetCollectionDetails] API endpoint.
+// The response contains the details in the same order as the input.
+// The child items are sorted according to the sort order.
+//
+// [GetCollectionDetails]: https://partner.steamgames.com/doc/webapi/ISteamRemoteStorage#GetCollectionDetails
+func (c *Client) CollectionDetailsApi(
+	ctx context.Context,
+	collectionIds ...uint64,
+) ([]CollectionDetailApi, error) {
 	data := url.Values{}
 	data.Set("collectioncount", strconv.Itoa(len(collectionIds)))
 	for i, id := range collectionIds {
 		data.Set(fmt.Sprintf("publishedfileids[%d]", i), strconv.FormatUint(id, 10))
 	}
 
-	client := &http.Client{}
-	r, _ := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		"https://api.steampowered.com/ISteamRemoteStorage/GetCollectionDetails/v1/",
-		strings.NewReader(data.Encode()),
-	)
-	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "ISteamRemoteStorage/GetCollectionDetails",
+		method:   http.MethodPost,
+		url:      c.apiBaseUrl + "/ISteamRemoteStorage/GetCollectionDetails/v1/",
+		body:     data.Encode(),
+		header: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Accept":       {"application/json"},
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
+	defer cancel()
 
 	defer resp.Body.Close()
 	return CollectionDetailsApiFromReader(resp.Body, collectionIds...)
@@ -8,7 +8,6 @@ import (
 	"net/url"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-json-experiment/json"
@@ -24,11 +23,26 @@ type getPublishedFileDetailsResponseInner struct {
 }
 
 type fileDetailApi struct {
-	CreatorAppId int    `json:"creator_app_id"`
-	Id           uint64 `json:"publishedfileid,string"`
-	TimeCreated  int64  `json:"time_created"`
-	TimeUpdated  int64  `json:"time_updated"`
-	Title        string `json:"title"`
+	BanReason     string             `json:"ban_reason"`
+	Banned        int                `json:"banned"`
+	ContentFile   uint64             `json:"hcontent_file,string"`
+	Creator       uint64             `json:"creator,string"`
+	CreatorAppId  int                `json:"creator_app_id"`
+	Description   string             `json:"description"`
+	FileSize      uint64             `json:"file_size,string"`
+	Id            uint64             `json:"publishedfileid,string"`
+	PreviewUrl    string             `json:"preview_url"`
+	Result        Result             `json:"result"`
+	Subscriptions int                `json:"subscriptions"`
+	Tags          []fileDetailApiTag `json:"tags"`
+	TimeCreated   int64              `json:"time_created"`
+	TimeUpdated   int64              `json:"time_updated"`
+	Title         string             `json:"title"`
+	Visibility    int                `json:"visibility"`
+}
+
+type fileDetailApiTag struct {
+	Tag string `json:"tag"`
 }
 type FileDetailApi struct {
 	// The ID of the game that the workshop item relates to.
@@ -37,6 +51,77 @@ type FileDetailApi struct {
 	TimeCreated  time.Time
 	TimeUpdated  time.Time
 	Title        string
+	// The size of the workshop item in bytes.
+	FileSize uint64
+	// Whether Steam returned the details of the workshop item. If not, only Id is set.
+	Result Result
+	// Who can see the workshop item.
+	Visibility Visibility
+	// True if the workshop item has been banned by Steam.
+	Banned    bool
+	BanReason string
+	// The ID of the manifest of the content of the workshop item. Changes with every update of
+	// the content.
+	ContentFile uint64
+	// The Steam ID of the author of the workshop item.
+	Creator uint64
+	// The description of the workshop item in BBCode.
+	Description string
+	// The URL of the preview image of the workshop item.
+	PreviewUrl string
+	// The amount of users currently subscribed to the workshop item.
+	Subscriptions int
+	Tags          []string
+}
+
+// Available returns true if the workshop item exists, is not private, and is not banned.
+func (d FileDetailApi) Available() bool {
+	return d.Result == ResultOk && d.Visibility != VisibilityPrivate && !d.Banned
+}
+
+// Result is the result code Steam returns for every requested workshop item, see [EResult].
+//
+// [EResult]: https://partner.steamgames.com/doc/api/steam_api#EResult
+type Result int
+
+const (
+	ResultOk Result = 1
+	// ResultFileNotFound is returned for workshop items that do not exist or have been removed.
+	ResultFileNotFound Result = 9
+	// ResultAccessDenied is returned for workshop items that are not visible to the requester.
+	ResultAccessDenied Result = 15
+)
+
+func (r Result) String() string {
+	switch r {
+	case ResultOk:
+		return "ok"
+	case ResultFileNotFound:
+		return "file not found"
+	case ResultAccessDenied:
+		return "access denied"
+	default:
+		return fmt.Sprintf("result %d", int(r))
+	}
+}
+
+// Visibility determines who can see a workshop item.
+type Visibility int
+
+const (
+	VisibilityPublic Visibility = iota
+	VisibilityFriendsOnly
+	VisibilityPrivate
+	VisibilityUnlisted
+)
+
+// FileDetailsApi returns the details of the workshop items according to
+// the [GetPublishedFileDetails] API endpoint using the default client.
+// The response contains the details in the same order as the input.
+//
+// [GetPublishedFileDetails]: https://partner.steamgames.com/doc/webapi/ISteamRemoteStorage#GetPublishedFileDetails
+func FileDetailsApi(ctx context.Context, ids ...uint64) ([]FileDetailApi, error) {
+	return defaultClient.FileDetailsApi(ctx, ids...)
 }
 
 // FileDetailsApi returns the details of the workshop items according to
@@ -44,27 +129,27 @@ type FileDetailApi struct {
 // The response contains the details in the same order as the input.
 //
 // [GetPublishedFileDetails]: https://partner.steamgames.com/doc/webapi/ISteamRemoteStorage#GetPublishedFileDetails
-func FileDetailsApi(ctx context.Context, ids ...uint64) ([]FileDetailApi, error) {
+func (c *Client) FileDetailsApi(ctx context.Context, ids ...uint64) ([]FileDetailApi, error) {
 	data := url.Values{}
 	data.Set("itemcount", strconv.Itoa(len(ids)))
 	for i, id := range ids {
 		data.Set(fmt.Sprintf("publishedfileids[%d]", i), strconv.FormatUint(id, 10))
 	}
 
-	client := &http.Client{}
-	r, _ := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		"https://api.steampowered.com/ISteamRemoteStorage/GetPublishedFileDetails/v1/",
-		strings.NewReader(data.Encode()),
-	)
-	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Add("Accept", "application/json")
-
-	resp, err := client.Do(r)
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "ISteamRemoteStorage/GetPublishedFileDetails",
+		method:   http.MethodPost,
+		url:      c.apiBaseUrl + "/ISteamRemoteStorage/GetPublishedFileDetails/v1/",
+		body:     data.Encode(),
+		header: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Accept":       {"application/json"},
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
+	defer cancel()
 
 	defer resp.Body.Close()
 	return FileDetailsApiFromReader(resp.Body, ids...)
@@ -94,12 +179,31 @@ func FileDetailsApiFromReader(r io.Reader, ids ...uint64) ([]FileDetailApi, erro
 		if index < 0 {
 			return nil, fmt.Errorf("unexpected file detail returned %d", detail.Id)
 		}
+		if detail.Result != ResultOk {
+			result[index] = FileDetailApi{Id: detail.Id, Result: detail.Result}
+			continue
+		}
+		var tags []string
+		for _, tag := range detail.Tags {
+			tags = append(tags, tag.Tag)
+		}
 		result[index] = FileDetailApi{
-			CreatorAppId: detail.CreatorAppId,
-			Id:           detail.Id,
-			TimeCreated:  time.Unix(detail.TimeCreated, 0),
-			TimeUpdated:  time.Unix(detail.TimeUpdated, 0),
-			Title:        detail.Title,
+			BanReason:     detail.BanReason,
+			Banned:        detail.Banned != 0,
+			ContentFile:   detail.ContentFile,
+			Creator:       detail.Creator,
+			CreatorAppId:  detail.CreatorAppId,
+			Description:   detail.Description,
+			FileSize:      detail.FileSize,
+			Id:            detail.Id,
+			PreviewUrl:    detail.PreviewUrl,
+			Result:        detail.Result,
+			Subscriptions: detail.Subscriptions,
+			Tags:          tags,
+			TimeCreated:   time.Unix(detail.TimeCreated, 0),
+			TimeUpdated:   time.Unix(detail.TimeUpdated, 0),
+			Title:         detail.Title,
+			Visibility:    Visibility(detail.Visibility),
 		}
 	}
 
@@ -9,36 +9,45 @@ import (
 	"net/url"
 	"slices"
 	"strconv"
+	"strings"
 
 	"golang.org/x/net/html"
 )
 
 type FileDetailsWeb struct {
+	// The apps, such as DLC, that the workshop item requires.
+	RequiredApps  []FileDetailsRequiredApp
 	RequiredItems []FileDetailsRequiredItems
 	Title         string
 }
 
+type FileDetailsRequiredApp struct {
+	Id   int
+	Name string
+}
+
 type FileDetailsRequiredItems struct {
 	Id    uint64
 	Title string
 }
 
-// GetFileDetailsWeb fetches the HTML of the mod and extracts data from it.
+// GetFileDetailsWeb fetches the HTML of the mod using the default client and extracts data from
+// it.
 func GetFileDetailsWeb(ctx context.Context, id uint64) (FileDetailsWeb, error) {
-	client := &http.Client{}
-	r, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodGet,
-		fmt.Sprintf("https://steamcommunity.com/sharedfiles/filedetails/?id=%d", id),
-		nil,
-	)
-	if err != nil {
-		return FileDetailsWeb{}, err
-	}
-	resp, err := client.Do(r)
+	return defaultClient.GetFileDetailsWeb(ctx, id)
+}
+
+// GetFileDetailsWeb fetches the HTML of the mod and extracts data from it.
+func (c *Client) GetFileDetailsWeb(ctx context.Context, id uint64) (FileDetailsWeb, error) {
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "sharedfiles/filedetails",
+		method:   http.MethodGet,
+		url:      fmt.Sprintf("%s/sharedfiles/filedetails/?id=%d", c.communityBaseUrl, id),
+	})
 	if err != nil {
 		return FileDetailsWeb{}, err
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
 	return ExtractFileDetailsFromHtml(resp.Body)
@@ -50,6 +59,9 @@ var hrefAttr = []byte("href")
 var idAttr = []byte("id")
 var workshopItemTitleClass = []byte("workshopItemTitle")
 var requiredItemsId = []byte("RequiredItems")
+var requiredAppItemsId = []byte("RequiredAppItems")
+var requiredDlcContainerClass = []byte("requiredDLCContainer")
+var rightContentsId = []byte("rightContents")
 
 func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 	/*
@@ -57,6 +69,10 @@ func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 		RequiredItems:
 			id: #RequiredItems > a[href] extract id param
 			title: #RequiredItems > a > .requiredItem Text
+		RequiredApps:
+			id: (.requiredDLCContainer, #RequiredAppItems) a[href] extract app ID from store URL
+			name: (.requiredDLCContainer, #RequiredAppItems) a Text
+		Stop at the end of #rightContents, the sidebar containing the requirements.
 	*/
 	z := html.NewTokenizer(r)
 	var inBody bool
@@ -68,16 +84,25 @@ func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 		nextIsTitle
 		inRequiredItems
 		inRequiredItem
+		inRequiredApps
+		inRequiredApp
 		stop
 	)
 	result := FileDetailsWeb{}
 	// This requires proper HTML (every tag should be closed)
 	var nextRequiredItem FileDetailsRequiredItems
+	var nextRequiredApp FileDetailsRequiredApp
 	inRequiredItemsTracker := &depthTracker{}
 	inRequiredItemTracker := &depthTracker{}
+	inRequiredAppsTracker := &depthTracker{}
+	inRequiredAppTracker := &depthTracker{}
+	inRightContentsTracker := &depthTracker{}
 	trackers := []*depthTracker{
 		inRequiredItemTracker,
 		inRequiredItemsTracker,
+		inRequiredAppTracker,
+		inRequiredAppsTracker,
+		inRightContentsTracker,
 	}
 
 	for {
@@ -117,10 +142,21 @@ func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 				if bytes.Equal(key, classAttr) && bytes.Equal(val, workshopItemTitleClass) {
 					state = nextIsTitle
 					break
+				} else if bytes.Equal(key, idAttr) && bytes.Equal(val, rightContentsId) {
+					inRightContentsTracker.Reset(slices.Clone(tagName), func() {
+						state = stop
+					})
 				} else if bytes.Equal(key, idAttr) && bytes.Equal(val, requiredItemsId) {
 					state = inRequiredItems
 					inRequiredItemsTracker.Reset(slices.Clone(tagName), func() {
-						state = stop
+						state = nothing
+					})
+					break
+				} else if bytes.Equal(key, idAttr) && bytes.Equal(val, requiredAppItemsId) ||
+					bytes.Equal(key, classAttr) && bytes.Equal(val, requiredDlcContainerClass) {
+					state = inRequiredApps
+					inRequiredAppsTracker.Reset(slices.Clone(tagName), func() {
+						state = nothing
 					})
 					break
 				} else if state == inRequiredItems &&
@@ -144,6 +180,22 @@ func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 						nextRequiredItem = FileDetailsRequiredItems{}
 					})
 					break
+				} else if state == inRequiredApps &&
+					len(tagName) == 1 &&
+					tagName[0] == 'a' &&
+					bytes.Equal(key, hrefAttr) {
+					appId, ok := appIdFromStoreUrl(string(val))
+					if !ok {
+						break
+					}
+					nextRequiredApp.Id = appId
+					state = inRequiredApp
+					inRequiredAppTracker.Reset(slices.Clone(tagName), func() {
+						state = inRequiredApps
+						result.RequiredApps = addRequiredApp(result.RequiredApps, nextRequiredApp)
+						nextRequiredApp = FileDetailsRequiredApp{}
+					})
+					break
 				}
 
 				if !moreAttr {
@@ -162,7 +214,42 @@ func ExtractFileDetailsFromHtml(r io.Reader) (FileDetailsWeb, error) {
 				state = nothing
 			case inRequiredItem:
 				nextRequiredItem.Title += string(bytes.TrimSpace(z.Text()))
+			case inRequiredApp:
+				nextRequiredApp.Name += string(bytes.TrimSpace(z.Text()))
 			}
 		}
 	}
 }
+
+// appIdFromStoreUrl returns the app ID of a Steam store URL such as
+// https://store.steampowered.com/app/1681170/Arma_3_Creator_DLC_Western_Sahara/.
+func appIdFromStoreUrl(storeUrl string) (int, bool) {
+	parsedUrl, err := url.Parse(storeUrl)
+	if err != nil {
+		return 0, false
+	}
+	segments := strings.Split(strings.Trim(parsedUrl.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "app" {
+		return 0, false
+	}
+	appId, err := strconv.Atoi(segments[1])
+	if err != nil {
+		return 0, false
+	}
+	return appId, true
+}
+
+// addRequiredApp adds the app to apps. An app that is linked multiple times, e.g. by both its
+// image and its name, is only added once.
+func addRequiredApp(apps []FileDetailsRequiredApp, app FileDetailsRequiredApp) []FileDetailsRequiredApp {
+	index := slices.IndexFunc(apps, func(a FileDetailsRequiredApp) bool {
+		return a.Id == app.Id
+	})
+	if index < 0 {
+		return append(apps, app)
+	}
+	if apps[index].Name == "" {
+		apps[index].Name = app.Name
+	}
+	return apps
+}
@@ -12,6 +12,9 @@ import _ "embed"
 //go:embed testdata/workshop_2950011244.html
 var workshopDetail []byte
 
+//go:embed testdata/workshop_required_dlc.html
+var workshopDetailRequiredDlc []byte
+
 func TestExtractFileDetailsFromHtml(t *testing.T) {
 	actual, err := steamworkshop.ExtractFileDetailsFromHtml(bytes.NewReader(workshopDetail))
 	assert.NoError(t, err)
@@ -47,6 +50,31 @@ func TestExtractFileDetailsFromHtml(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestExtractFileDetailsFromHtml_RequiredDlc(t *testing.T) {
+	actual, err := steamworkshop.ExtractFileDetailsFromHtml(bytes.NewReader(workshopDetailRequiredDlc))
+	assert.NoError(t, err)
+	expected := steamworkshop.FileDetailsWeb{
+		RequiredApps: []steamworkshop.FileDetailsRequiredApp{
+			{
+				Id:   1681170,
+				Name: "Arma 3 Creator DLC: Western Sahara",
+			},
+			{
+				Id:   304380,
+				Name: "Arma 3 Helicopters",
+			},
+		},
+		RequiredItems: []steamworkshop.FileDetailsRequiredItems{
+			{
+				Id:    450814997,
+				Title: "CBA_A3",
+			},
+		},
+		Title: "Western Sahara Compatibility",
+	}
+	assert.Equal(t, expected, actual)
+}
+
 func BenchmarkExtractFileDetailsFromHtml(b *testing.B) {
 	for b.Loop() {
 		_, err := steamworkshop.ExtractFileDetailsFromHtml(bytes.NewReader(workshopDetail))
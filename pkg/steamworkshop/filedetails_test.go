@@ -2,6 +2,7 @@ package steamworkshop_test
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,14 +18,53 @@ var modAceApiResponse []byte
 func TestFileDetailsApiFromReader(t *testing.T) {
 	actual, err := steamworkshop.FileDetailsApiFromReader(bytes.NewBuffer(modAceApiResponse), 463939057)
 	assert.NoError(t, err)
+	if !assert.Len(t, actual, 1) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(
+		actual[0].Description,
+		"[h1]Advanced Combat Environment 3 (ACE3)[/h1]\r\n",
+	))
 	expected := []steamworkshop.FileDetailApi{
 		{
-			CreatorAppId: 107410,
-			Id:           463939057,
-			TimeCreated:  time.Unix(1434653369, 0),
-			TimeUpdated:  time.Unix(1752589679, 0),
-			Title:        "ace",
+			ContentFile:   3524971499552554428,
+			Creator:       76561198194647182,
+			CreatorAppId:  107410,
+			Description:   actual[0].Description,
+			FileSize:      227199182,
+			Id:            463939057,
+			PreviewUrl:    "https://images.steamusercontent.com/ugc/964230428541162652/F7DC4A5DD2A4896E2D572D7E9E085489426FC64B/",
+			Result:        steamworkshop.ResultOk,
+			Subscriptions: 1369603,
+			Tags:          []string{"Mod", "Content Review", "x64"},
+			TimeCreated:   time.Unix(1434653369, 0),
+			TimeUpdated:   time.Unix(1752589679, 0),
+			Title:         "ace",
 		},
 	}
 	assert.Equal(t, expected, actual)
 }
+
+func TestFileDetailsApiFromReader_Unavailable(t *testing.T) {
+	response := `{"response":{"result":1,"resultcount":3,"publishedfiledetails":[
+		{"publishedfileid":"1","result":9},
+		{"publishedfileid":"2","result":1,"title":"Private","visibility":2,"banned":0},
+		{"publishedfileid":"3","result":1,"title":"Banned","banned":1,"ban_reason":"Copyright"}
+	]}}`
+	actual, err := steamworkshop.FileDetailsApiFromReader(bytes.NewBufferString(response), 1, 2, 3)
+	assert.NoError(t, err)
+	if !assert.Len(t, actual, 3) {
+		return
+	}
+
+	assert.Equal(t, steamworkshop.FileDetailApi{
+		Id:     1,
+		Result: steamworkshop.ResultFileNotFound,
+	}, actual[0])
+	assert.False(t, actual[0].Available())
+	assert.Equal(t, steamworkshop.VisibilityPrivate, actual[1].Visibility)
+	assert.False(t, actual[1].Available())
+	assert.True(t, actual[2].Banned)
+	assert.Equal(t, "Copyright", actual[2].BanReason)
+	assert.False(t, actual[2].Available())
+}
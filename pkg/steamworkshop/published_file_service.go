@@ -0,0 +1,274 @@
+package steamworkshop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// ErrNoApiKey is returned by requests that require a Steam Web API key when the client has none.
+var ErrNoApiKey = errors.New("a Steam Web API key is required")
+
+type getDetailsResponse struct {
+	Response getDetailsResponseInner `json:"response"`
+}
+
+type getDetailsResponseInner struct {
+	PublishedFileDetails []publishedFileDetailApi `json:"publishedfiledetails"`
+}
+
+type publishedFileDetailApi struct {
+	BanReason     string                  `json:"ban_reason"`
+	Banned        bool                    `json:"banned"`
+	Children      []publishedFileChildApi `json:"children"`
+	ContentFile   uint64                  `json:"hcontent_file,string"`
+	Creator       uint64                  `json:"creator,string"`
+	CreatorAppId  int                     `json:"creator_appid"`
+	Description   string                  `json:"file_description"`
+	FileSize      uint64                  `json:"file_size,string"`
+	Id            uint64                  `json:"publishedfileid,string"`
+	PreviewUrl    string                  `json:"preview_url"`
+	Result        Result                  `json:"result"`
+	Subscriptions int                     `json:"subscriptions"`
+	Tags          []fileDetailApiTag      `json:"tags"`
+	TimeCreated   int64                   `json:"time_created"`
+	TimeUpdated   int64                   `json:"time_updated"`
+	Title         string                  `json:"title"`
+	Visibility    int                     `json:"visibility"`
+}
+
+type publishedFileChildApi struct {
+	PublishedFileId uint64                   `json:"publishedfileid,string"`
+	SortOrder       int                      `json:"sortorder"`
+	FileType        CollectionDetailFileType `json:"file_type"`
+}
+
+// PublishedFileDetailApi contains the details of a workshop item according to the
+// [IPublishedFileService/GetDetails] API endpoint.
+//
+// [IPublishedFileService/GetDetails]: https://steamapi.xpaw.me/#IPublishedFileService/GetDetails
+type PublishedFileDetailApi struct {
+	FileDetailApi
+	// The required items of a workshop item, or the items of a collection, sorted according to
+	// the sort order.
+	Children []CollectionDetailItem
+}
+
+// PublishedFileDetailsApi returns the details of the workshop items, including their required
+// items, according to the [IPublishedFileService/GetDetails] API endpoint. Requires an API key,
+// see [WithApiKey].
+// The response contains the details in the same order as the input.
+//
+// [IPublishedFileService/GetDetails]: https://steamapi.xpaw.me/#IPublishedFileService/GetDetails
+func (c *Client) PublishedFileDetailsApi(
+	ctx context.Context,
+	ids ...uint64,
+) ([]PublishedFileDetailApi, error) {
+	if c.apiKey == "" {
+		return nil, ErrNoApiKey
+	}
+
+	query := url.Values{}
+	query.Set("key", c.apiKey)
+	query.Set("includechildren", "true")
+	query.Set("includetags", "true")
+	for i, id := range ids {
+		query.Set(fmt.Sprintf("publishedfileids[%d]", i), strconv.FormatUint(id, 10))
+	}
+
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "IPublishedFileService/GetDetails",
+		method:   http.MethodGet,
+		url:      c.apiBaseUrl + "/IPublishedFileService/GetDetails/v1/?" + query.Encode(),
+		header:   http.Header{"Accept": {"application/json"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	defer resp.Body.Close()
+	return PublishedFileDetailsApiFromReader(resp.Body, ids...)
+}
+
+// PublishedFileDetailsApiFromReader parses a response as received from the
+// [IPublishedFileService/GetDetails] API endpoint.
+// The response contains the details in the same order as the input.
+//
+// [IPublishedFileService/GetDetails]: https://steamapi.xpaw.me/#IPublishedFileService/GetDetails
+func PublishedFileDetailsApiFromReader(r io.Reader, ids ...uint64) ([]PublishedFileDetailApi, error) {
+	var response getDetailsResponse
+	err := json.UnmarshalRead(r, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Response.PublishedFileDetails) != len(ids) {
+		return nil, fmt.Errorf(
+			"expected %d results, got %d",
+			len(ids),
+			len(response.Response.PublishedFileDetails),
+		)
+	}
+
+	result := make([]PublishedFileDetailApi, len(response.Response.PublishedFileDetails))
+	for _, detail := range response.Response.PublishedFileDetails {
+		index := slices.Index(ids, detail.Id)
+		if index < 0 {
+			return nil, fmt.Errorf("unexpected file detail returned %d", detail.Id)
+		}
+		result[index] = detail.toPublishedFileDetailApi()
+	}
+
+	return result, nil
+}
+
+// toPublishedFileDetailApi converts the response of the API to the exported type.
+func (detail publishedFileDetailApi) toPublishedFileDetailApi() PublishedFileDetailApi {
+	if detail.Result != ResultOk {
+		return PublishedFileDetailApi{
+			FileDetailApi: FileDetailApi{Id: detail.Id, Result: detail.Result},
+		}
+	}
+
+	var tags []string
+	for _, tag := range detail.Tags {
+		tags = append(tags, tag.Tag)
+	}
+	children := make([]CollectionDetailItem, 0, len(detail.Children))
+	for _, child := range detail.Children {
+		children = append(children, CollectionDetailItem{
+			Id:        child.PublishedFileId,
+			SortOrder: child.SortOrder,
+			Type:      child.FileType,
+		})
+	}
+	slices.SortFunc(children, func(a, b CollectionDetailItem) int {
+		return a.SortOrder - b.SortOrder
+	})
+
+	return PublishedFileDetailApi{
+		FileDetailApi: FileDetailApi{
+			BanReason:     detail.BanReason,
+			Banned:        detail.Banned,
+			ContentFile:   detail.ContentFile,
+			Creator:       detail.Creator,
+			CreatorAppId:  detail.CreatorAppId,
+			Description:   detail.Description,
+			FileSize:      detail.FileSize,
+			Id:            detail.Id,
+			PreviewUrl:    detail.PreviewUrl,
+			Result:        detail.Result,
+			Subscriptions: detail.Subscriptions,
+			Tags:          tags,
+			TimeCreated:   time.Unix(detail.TimeCreated, 0),
+			TimeUpdated:   time.Unix(detail.TimeUpdated, 0),
+			Title:         detail.Title,
+			Visibility:    Visibility(detail.Visibility),
+		},
+		Children: children,
+	}
+}
+
+const (
+	// Orders the results of QueryFiles by their relevance to the search text.
+	queryTypeRankedByTextSearch = 12
+	// Makes QueryFiles only return workshop items, no collections.
+	matchingFileTypeItems = 0
+)
+
+// QueryFilesOpts contains the parameters of a search for workshop items.
+type QueryFilesOpts struct {
+	// The ID of the app whose workshop is searched, e.g. 107410 for Arma 3.
+	AppId int
+	// The text to search for in the workshop items.
+	SearchText string
+	// The maximum amount of results, at most 100. Steam defaults to 1 if not set.
+	NumPerPage int
+}
+
+// QueryFilesResult contains the workshop items that match a search.
+type QueryFilesResult struct {
+	// The total amount of workshop items that match the search, which can exceed the amount of
+	// returned items.
+	Total int
+	// The matching workshop items, most relevant first.
+	Items []PublishedFileDetailApi
+}
+
+type queryFilesResponse struct {
+	Response queryFilesResponseInner `json:"response"`
+}
+
+type queryFilesResponseInner struct {
+	Total                int                      `json:"total"`
+	PublishedFileDetails []publishedFileDetailApi `json:"publishedfiledetails"`
+}
+
+// QueryFilesApi searches the workshop items of an app using the [IPublishedFileService/QueryFiles]
+// API endpoint. Only workshop items are returned, not collections. Requires an API key, see
+// [WithApiKey].
+//
+// [IPublishedFileService/QueryFiles]: https://steamapi.xpaw.me/#IPublishedFileService/QueryFiles
+func (c *Client) QueryFilesApi(
+	ctx context.Context,
+	opts QueryFilesOpts,
+) (QueryFilesResult, error) {
+	if c.apiKey == "" {
+		return QueryFilesResult{}, ErrNoApiKey
+	}
+
+	query := url.Values{}
+	query.Set("key", c.apiKey)
+	query.Set("query_type", strconv.Itoa(queryTypeRankedByTextSearch))
+	query.Set("appid", strconv.Itoa(opts.AppId))
+	query.Set("creator_appid", strconv.Itoa(opts.AppId))
+	query.Set("search_text", opts.SearchText)
+	query.Set("filetype", strconv.Itoa(matchingFileTypeItems))
+	query.Set("return_tags", "true")
+	if opts.NumPerPage > 0 {
+		query.Set("numperpage", strconv.Itoa(opts.NumPerPage))
+	}
+
+	resp, cancel, err := c.do(ctx, request{
+		endpoint: "IPublishedFileService/QueryFiles",
+		method:   http.MethodGet,
+		url:      c.apiBaseUrl + "/IPublishedFileService/QueryFiles/v1/?" + query.Encode(),
+		header:   http.Header{"Accept": {"application/json"}},
+	})
+	if err != nil {
+		return QueryFilesResult{}, err
+	}
+	defer cancel()
+
+	defer resp.Body.Close()
+	return QueryFilesApiFromReader(resp.Body)
+}
+
+// QueryFilesApiFromReader parses a response as received from the
+// [IPublishedFileService/QueryFiles] API endpoint.
+//
+// [IPublishedFileService/QueryFiles]: https://steamapi.xpaw.me/#IPublishedFileService/QueryFiles
+func QueryFilesApiFromReader(r io.Reader) (QueryFilesResult, error) {
+	var response queryFilesResponse
+	err := json.UnmarshalRead(r, &response)
+	if err != nil {
+		return QueryFilesResult{}, err
+	}
+
+	result := QueryFilesResult{
+		Total: response.Response.Total,
+		Items: make([]PublishedFileDetailApi, 0, len(response.Response.PublishedFileDetails)),
+	}
+	for _, detail := range response.Response.PublishedFileDetails {
+		result.Items = append(result.Items, detail.toPublishedFileDetailApi())
+	}
+	return result, nil
+}
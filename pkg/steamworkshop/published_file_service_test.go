@@ -0,0 +1,148 @@
+package steamworkshop_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatthiasKunnen/boiler/pkg/steamworkshop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishedFileDetailsApiFromReader(t *testing.T) {
+	response := `{"response":{"publishedfiledetails":[
+		{"result":9,"publishedfileid":"1"},
+		{
+			"result":1,
+			"publishedfileid":"2950011244",
+			"creator":"76561198194647182",
+			"creator_appid":107410,
+			"file_size":"1024",
+			"hcontent_file":"3524971499552554428",
+			"title":"Sail to South_Eastern Asia",
+			"file_description":"[b]Sail[/b]",
+			"time_created":1434653369,
+			"time_updated":1752589679,
+			"visibility":0,
+			"banned":false,
+			"ban_reason":"",
+			"subscriptions":12,
+			"tags":[{"tag":"Mod","display_name":"Mod"}],
+			"num_children":2,
+			"children":[
+				{"publishedfileid":"463939057","sortorder":1,"file_type":0},
+				{"publishedfileid":"450814997","sortorder":0,"file_type":0}
+			]
+		}
+	]}}`
+	actual, err := steamworkshop.PublishedFileDetailsApiFromReader(
+		strings.NewReader(response),
+		2950011244,
+		1,
+	)
+	assert.NoError(t, err)
+	expected := []steamworkshop.PublishedFileDetailApi{
+		{
+			FileDetailApi: steamworkshop.FileDetailApi{
+				ContentFile:   3524971499552554428,
+				Creator:       76561198194647182,
+				CreatorAppId:  107410,
+				Description:   "[b]Sail[/b]",
+				FileSize:      1024,
+				Id:            2950011244,
+				Result:        steamworkshop.ResultOk,
+				Subscriptions: 12,
+				Tags:          []string{"Mod"},
+				TimeCreated:   time.Unix(1434653369, 0),
+				TimeUpdated:   time.Unix(1752589679, 0),
+				Title:         "Sail to South_Eastern Asia",
+			},
+			Children: []steamworkshop.CollectionDetailItem{
+				{450814997, 0, steamworkshop.CollectionDetailFileTypeWorkshopItem},
+				{463939057, 1, steamworkshop.CollectionDetailFileTypeWorkshopItem},
+			},
+		},
+		{
+			FileDetailApi: steamworkshop.FileDetailApi{
+				Id:     1,
+				Result: steamworkshop.ResultFileNotFound,
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestQueryFilesApiFromReader(t *testing.T) {
+	response := `{"response":{"total":42,"publishedfiledetails":[
+		{
+			"result":1,
+			"publishedfileid":"463939057",
+			"creator":"76561198194647182",
+			"creator_appid":107410,
+			"file_size":"2048",
+			"title":"ace",
+			"time_created":1434653369,
+			"time_updated":1752589679,
+			"visibility":0,
+			"tags":[{"tag":"Mod","display_name":"Mod"}]
+		}
+	]}}`
+	actual, err := steamworkshop.QueryFilesApiFromReader(strings.NewReader(response))
+	assert.NoError(t, err)
+	expected := steamworkshop.QueryFilesResult{
+		Total: 42,
+		Items: []steamworkshop.PublishedFileDetailApi{
+			{
+				FileDetailApi: steamworkshop.FileDetailApi{
+					Creator:      76561198194647182,
+					CreatorAppId: 107410,
+					FileSize:     2048,
+					Id:           463939057,
+					Result:       steamworkshop.ResultOk,
+					Tags:         []string{"Mod"},
+					TimeCreated:  time.Unix(1434653369, 0),
+					TimeUpdated:  time.Unix(1752589679, 0),
+					Title:        "ace",
+				},
+				Children: []steamworkshop.CollectionDetailItem{},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	actual, err = steamworkshop.QueryFilesApiFromReader(strings.NewReader(`{"response":{"total":0}}`))
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		steamworkshop.QueryFilesResult{Items: []steamworkshop.PublishedFileDetailApi{}},
+		actual,
+	)
+}
+
+func TestClient_PublishedFileDetailsApi_ApiKey(t *testing.T) {
+	_, err := steamworkshop.NewClient().PublishedFileDetailsApi(context.Background(), 1)
+	assert.ErrorIs(t, err, steamworkshop.ErrNoApiKey)
+
+	server := httptest.NewServer(nil)
+	server.Close()
+	client := steamworkshop.NewClient(
+		steamworkshop.WithApiBaseUrl(server.URL),
+		steamworkshop.WithApiKey("secret"),
+		steamworkshop.WithRetries(0, 0),
+	)
+	_, err = client.PublishedFileDetailsApi(context.Background(), 1)
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "secret")
+		assert.Contains(t, err.Error(), "key=REDACTED")
+	}
+}
+
+func TestClient_QueryFilesApi_ApiKey(t *testing.T) {
+	_, err := steamworkshop.NewClient().QueryFilesApi(
+		context.Background(),
+		steamworkshop.QueryFilesOpts{AppId: 107410, SearchText: "ace"},
+	)
+	assert.ErrorIs(t, err, steamworkshop.ErrNoApiKey)
+}
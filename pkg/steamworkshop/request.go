@@ -0,0 +1,178 @@
+package steamworkshop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestError is returned when a request to Steam fails, after it has been retried if the
+// failure was transient.
+type RequestError struct {
+	// The endpoint that was requested, e.g. ISteamRemoteStorage/GetPublishedFileDetails.
+	Endpoint string
+	// The HTTP status code of the last response, 0 if no response was received.
+	StatusCode int
+	// The amount of times the request was sent.
+	Attempts int
+	// The error of the last attempt, nil if a response with an unexpected status was received.
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf(
+			"request to %s failed after %d attempt(s): %v",
+			e.Endpoint,
+			e.Attempts,
+			e.Err,
+		)
+	}
+	return fmt.Sprintf(
+		"request to %s failed after %d attempt(s): status %d %s",
+		e.Endpoint,
+		e.Attempts,
+		e.StatusCode,
+		http.StatusText(e.StatusCode),
+	)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// request is a request to Steam that can be sent multiple times.
+type request struct {
+	// Used in errors, e.g. ISteamRemoteStorage/GetPublishedFileDetails.
+	endpoint string
+	method   string
+	url      string
+	body     string
+	header   http.Header
+}
+
+// isRetryableStatus returns true if a response with the status might succeed when retried.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// do sends the request and returns the response if its status is 200 OK. Requests that fail due
+// to network errors, rate limiting, or server errors are retried with an exponential backoff.
+// The returned cancel function must be called once the body of the response has been read.
+func (c *Client) do(ctx context.Context, req request) (*http.Response, context.CancelFunc, error) {
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		resp, cancel, err := c.send(ctx, req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			return resp, cancel, nil
+		}
+
+		reqErr := &RequestError{Endpoint: req.endpoint, Attempts: attempt, Err: redactApiKey(err)}
+		retryable := ctx.Err() == nil
+		var delay time.Duration
+		if err == nil {
+			reqErr.StatusCode = resp.StatusCode
+			retryable = retryable && isRetryableStatus(resp.StatusCode)
+			delay = retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			resp.Body.Close()
+		}
+		cancel()
+
+		if !retryable || attempt > c.retries {
+			return nil, nil, reqErr
+		}
+		if delay <= 0 {
+			delay = withJitter(backoff)
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			reqErr.Err = ctx.Err()
+			return nil, nil, reqErr
+		case <-timer.C:
+		}
+	}
+}
+
+// send sends the request once, with the user agent and timeout of the client.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, context.CancelFunc, error) {
+	err := c.rateLimit.wait(ctx)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, req.url, strings.NewReader(req.body))
+	if err != nil {
+		return nil, cancel, err
+	}
+	for key, values := range req.header {
+		r.Header[key] = values
+	}
+	if c.userAgent != "" {
+		r.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, cancel, err
+	}
+	return resp, cancel, nil
+}
+
+// redactApiKey removes the API key from the URL reported by errors of the HTTP client.
+func redactApiKey(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return err
+	}
+	query := u.Query()
+	if !query.Has("key") {
+		return err
+	}
+	query.Set("key", "REDACTED")
+	u.RawQuery = query.Encode()
+	urlErr.URL = u.String()
+	return err
+}
+
+// retryAfter returns the delay requested by the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date. Returns 0 if the value is absent or invalid.
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// withJitter returns a random duration between half of d and d, so that clients that failed at
+// the same time do not retry at the same time.
+func withJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2+1)
+}
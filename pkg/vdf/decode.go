@@ -0,0 +1,190 @@
+package vdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is returned when a value cannot be decoded into the given Go value.
+type DecodeError struct {
+	// The keys leading to the value that could not be decoded.
+	Path []string
+	Msg  string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("vdf: cannot decode %s: %s", strings.Join(e.Path, "."), e.Msg)
+}
+
+// Unmarshal parses the KeyValues text and stores the result in the value pointed to by v.
+// See [Object.Decode] for the supported types.
+func Unmarshal(data []byte, v any) error {
+	return UnmarshalRead(bytes.NewReader(data), v)
+}
+
+// UnmarshalRead is like [Unmarshal] but reads from r.
+func UnmarshalRead(r io.Reader, v any) error {
+	object, err := Parse(r)
+	if err != nil {
+		return err
+	}
+	return object.Decode(v)
+}
+
+var objectType = reflect.TypeFor[Object]()
+
+// Decode stores the object in the value pointed to by v.
+//
+// Objects can be decoded into structs, maps with string keys, [Object], and any.
+// Struct fields are matched case-insensitively using the name in the vdf tag, or the field name
+// if it has no tag. A tag of "-" skips the field. Keys without a matching field are ignored.
+// If a key occurs more than once, the last value wins, except when decoding into [Object].
+//
+// Strings can be decoded into strings, integers, floats, booleans ("0" or "1"), and any.
+func (o Object) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("vdf: Decode requires a non-nil pointer, got %T", v)
+	}
+	return decodeObject(o, rv.Elem(), nil)
+}
+
+func decodeObject(o Object, rv reflect.Value, path []string) error {
+	if rv.Type() == objectType {
+		rv.Set(reflect.ValueOf(o))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeObject(o, rv.Elem(), path)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			break
+		}
+		rv.Set(reflect.ValueOf(o.ToMap()))
+		return nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(o)))
+		}
+		for _, pair := range o {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			err := decodePair(pair, elem, append(path, pair.Key))
+			if err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(pair.Key).Convert(rv.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Struct:
+		for _, pair := range o {
+			field, ok := findField(rv, pair.Key)
+			if !ok {
+				continue
+			}
+			err := decodePair(pair, field, append(path, pair.Key))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &DecodeError{Path: path, Msg: fmt.Sprintf("an object cannot be stored in %s", rv.Type())}
+}
+
+func decodePair(pair Pair, rv reflect.Value, path []string) error {
+	if pair.IsObject() {
+		return decodeObject(pair.Object, rv, path)
+	}
+	return decodeString(pair.String, rv, path)
+}
+
+func decodeString(s string, rv reflect.Value, path []string) error {
+	fail := func(err error) error {
+		return &DecodeError{
+			Path: path,
+			Msg:  fmt.Sprintf("cannot store %q in %s: %v", s, rv.Type(), err),
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeString(s, rv.Elem(), path)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			break
+		}
+		rv.Set(reflect.ValueOf(s))
+		return nil
+	case reflect.String:
+		rv.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetFloat(f)
+		return nil
+	}
+
+	return &DecodeError{Path: path, Msg: fmt.Sprintf("a string cannot be stored in %s", rv.Type())}
+}
+
+// findField returns the exported struct field that matches the key.
+func findField(rv reflect.Value, key string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("vdf"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		if strings.EqualFold(name, key) {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
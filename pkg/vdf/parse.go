@@ -0,0 +1,285 @@
+package vdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SyntaxError is returned when the input is not valid KeyValues text.
+type SyntaxError struct {
+	// Line and column, starting at 1, at which the error was encountered.
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("vdf: syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse parses the KeyValues text into an object containing the top level pairs.
+func Parse(r io.Reader) (Object, error) {
+	p := &parser{r: bufio.NewReader(r), line: 1}
+	return p.parseObject(true)
+}
+
+// ParseBytes parses the KeyValues text into an object containing the top level pairs.
+func ParseBytes(data []byte) (Object, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	// A platform conditional such as [$WIN32]. These are ignored.
+	tokenConditional
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	line   int
+	column int
+}
+
+type parser struct {
+	r      *bufio.Reader
+	line   int
+	column int
+	peeked *token
+}
+
+func (p *parser) parseObject(root bool) (Object, error) {
+	result := Object{}
+	for {
+		key, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key.kind {
+		case tokenEOF:
+			if root {
+				return result, nil
+			}
+			return nil, p.errorAt(key, "unexpected end of input, expected }")
+		case tokenClose:
+			if root {
+				return nil, p.errorAt(key, "unexpected }")
+			}
+			return result, nil
+		case tokenOpen:
+			return nil, p.errorAt(key, "unexpected {, expected key")
+		case tokenConditional:
+			return nil, p.errorAt(key, "unexpected conditional, expected key")
+		}
+
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		pair := Pair{Key: key.value}
+		switch value.kind {
+		case tokenEOF:
+			return nil, p.errorAt(value, fmt.Sprintf("unexpected end of input, expected value of %q", key.value))
+		case tokenClose:
+			return nil, p.errorAt(value, fmt.Sprintf("unexpected }, expected value of %q", key.value))
+		case tokenConditional:
+			return nil, p.errorAt(value, fmt.Sprintf("unexpected conditional, expected value of %q", key.value))
+		case tokenOpen:
+			pair.Object, err = p.parseObject(false)
+			if err != nil {
+				return nil, err
+			}
+		case tokenString:
+			pair.String = value.value
+		}
+
+		err = p.skipConditional()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, pair)
+	}
+}
+
+// skipConditional consumes a conditional if it is the next token.
+func (p *parser) skipConditional() error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokenConditional {
+		p.peeked = &t
+	}
+	return nil
+}
+
+func (p *parser) errorAt(t token, msg string) error {
+	return &SyntaxError{Line: t.line, Column: t.column, Msg: msg}
+}
+
+func (p *parser) readRune() (rune, error) {
+	c, _, err := p.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if c == '\n' {
+		p.line++
+		p.column = 0
+	} else {
+		p.column++
+	}
+	return c, nil
+}
+
+func (p *parser) peekRune() (rune, error) {
+	c, _, err := p.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	return c, p.r.UnreadRune()
+}
+
+func (p *parser) next() (token, error) {
+	if p.peeked != nil {
+		t := *p.peeked
+		p.peeked = nil
+		return t, nil
+	}
+
+	for {
+		c, err := p.readRune()
+		if errors.Is(err, io.EOF) {
+			return token{kind: tokenEOF, line: p.line, column: p.column + 1}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+		start := token{line: p.line, column: p.column}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\ufeff':
+			continue
+		case c == '/':
+			next, err := p.peekRune()
+			if err != nil || next != '/' {
+				return token{}, p.errorAt(start, "unexpected /, expected //")
+			}
+			_, err = p.r.ReadString('\n')
+			p.line++
+			p.column = 0
+			if err != nil && !errors.Is(err, io.EOF) {
+				return token{}, err
+			}
+			continue
+		case c == '{':
+			start.kind = tokenOpen
+			return start, nil
+		case c == '}':
+			start.kind = tokenClose
+			return start, nil
+		case c == '"':
+			start.kind = tokenString
+			start.value, err = p.readQuoted(start)
+			return start, err
+		case c == '[':
+			start.kind = tokenConditional
+			start.value, err = p.readUntil(start, ']')
+			return start, err
+		default:
+			start.kind = tokenString
+			start.value, err = p.readUnquoted(c)
+			return start, err
+		}
+	}
+}
+
+func (p *parser) readQuoted(start token) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := p.readRune()
+		if errors.Is(err, io.EOF) {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			escaped, err := p.readRune()
+			if errors.Is(err, io.EOF) {
+				return "", p.errorAt(start, "unterminated string")
+			}
+			if err != nil {
+				return "", err
+			}
+			switch escaped {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case '\\', '"':
+				sb.WriteRune(escaped)
+			default:
+				// Unknown escape sequences, such as in Windows paths, are kept as is.
+				sb.WriteRune('\\')
+				sb.WriteRune(escaped)
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+}
+
+func (p *parser) readUntil(start token, end rune) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := p.readRune()
+		if errors.Is(err, io.EOF) {
+			return "", p.errorAt(start, fmt.Sprintf("unexpected end of input, expected %c", end))
+		}
+		if err != nil {
+			return "", err
+		}
+		if c == end {
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readUnquoted reads a token that is not enclosed in quotes. It ends at whitespace, a quote, or
+// a brace.
+func (p *parser) readUnquoted(first rune) (string, error) {
+	var sb strings.Builder
+	sb.WriteRune(first)
+	for {
+		c, err := p.peekRune()
+		if errors.Is(err, io.EOF) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n', '"', '{', '}':
+			return sb.String(), nil
+		}
+		_, _ = p.readRune()
+		sb.WriteRune(c)
+	}
+}
@@ -0,0 +1,117 @@
+// Package vdf reads and writes Valve's KeyValues text format, used by files such as
+// appmanifest_<appid>.acf, appworkshop_<appid>.acf, config.vdf and the output of
+// steamcmd's app_info_print.
+//
+// A document consists of keys, each followed by either a string or an object enclosed in braces:
+//
+//	"AppState"
+//	{
+//		"appid"		"233780"
+//		"buildid"	"12345678"
+//	}
+//
+// Keys are case-insensitive by convention and may occur more than once.
+package vdf
+
+import (
+	"strings"
+)
+
+// Object is an ordered list of key-value pairs. Keys may occur more than once.
+type Object []Pair
+
+// Pair is a key with either a string or an object as value.
+type Pair struct {
+	Key string
+	// The value if the pair is not an object.
+	String string
+	// The value if the pair is an object. Nil if the value is a string, non-nil otherwise, even
+	// if the object is empty.
+	Object Object
+}
+
+// IsObject returns true if the value of the pair is an object.
+func (p Pair) IsObject() bool {
+	return p.Object != nil
+}
+
+// Get returns the first pair with the given key. Keys are compared case-insensitively.
+func (o Object) Get(key string) (Pair, bool) {
+	for _, pair := range o {
+		if strings.EqualFold(pair.Key, key) {
+			return pair, true
+		}
+	}
+	return Pair{}, false
+}
+
+// Lookup follows the path of keys and returns the pair at the end of it.
+// Keys are compared case-insensitively.
+func (o Object) Lookup(path ...string) (Pair, bool) {
+	if len(path) == 0 {
+		return Pair{}, false
+	}
+
+	current := o
+	for i, key := range path {
+		pair, ok := current.Get(key)
+		if !ok {
+			return Pair{}, false
+		}
+		if i == len(path)-1 {
+			return pair, true
+		}
+		if !pair.IsObject() {
+			return Pair{}, false
+		}
+		current = pair.Object
+	}
+
+	return Pair{}, false
+}
+
+// LookupString returns the string at the end of the path.
+// Returns false if the path does not exist or does not point to a string.
+func (o Object) LookupString(path ...string) (string, bool) {
+	pair, ok := o.Lookup(path...)
+	if !ok || pair.IsObject() {
+		return "", false
+	}
+	return pair.String, true
+}
+
+// LookupObject returns the object at the end of the path.
+// Returns false if the path does not exist or does not point to an object.
+func (o Object) LookupObject(path ...string) (Object, bool) {
+	pair, ok := o.Lookup(path...)
+	if !ok || !pair.IsObject() {
+		return nil, false
+	}
+	return pair.Object, true
+}
+
+// Set replaces the first pair with the key of pair, or appends pair if there is none.
+// Keys are compared case-insensitively. Returns the updated object.
+func (o Object) Set(pair Pair) Object {
+	for i := range o {
+		if strings.EqualFold(o[i].Key, pair.Key) {
+			o[i] = pair
+			return o
+		}
+	}
+	return append(o, pair)
+}
+
+// ToMap converts the object to nested maps. Values are either a string or a map[string]any.
+// If a key occurs more than once, the last value wins.
+func (o Object) ToMap() map[string]any {
+	result := make(map[string]any, len(o))
+	for _, pair := range o {
+		if pair.IsObject() {
+			result[pair.Key] = pair.Object.ToMap()
+		} else {
+			result[pair.Key] = pair.String
+		}
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package vdf_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MatthiasKunnen/boiler/pkg/vdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/appmanifest_233780.acf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	object, err := vdf.Parse(f)
+	assert.NoError(t, err)
+
+	buildId, ok := object.LookupString("appstate", "BuildId")
+	assert.True(t, ok)
+	assert.Equal(t, "14521731", buildId)
+
+	path, _ := object.LookupString("AppState", "path")
+	assert.Equal(t, `C:\Games\Arma "3"`, path)
+
+	depot, ok := object.LookupObject("AppState", "InstalledDepots", "233781")
+	assert.True(t, ok)
+	assert.Equal(t, vdf.Object{
+		{Key: "manifest", String: "6183564618733453744"},
+		{Key: "size", String: "2684354560"},
+	}, depot)
+
+	_, ok = object.LookupString("AppState", "UserConfig")
+	assert.False(t, ok, "UserConfig is an object, not a string")
+	_, ok = object.LookupObject("AppState", "appid", "nested")
+	assert.False(t, ok)
+}
+
+func TestParse_EmptyObject(t *testing.T) {
+	object, err := vdf.ParseBytes([]byte(`"a" {} "b" ""`))
+	assert.NoError(t, err)
+
+	a, _ := object.Get("a")
+	assert.True(t, a.IsObject())
+	assert.Empty(t, a.Object)
+
+	b, _ := object.Get("b")
+	assert.False(t, b.IsObject())
+}
+
+func TestObject_Set(t *testing.T) {
+	object := vdf.Object{
+		{Key: "appid", String: "107410"},
+		{Key: "WorkshopItemsInstalled", Object: vdf.Object{}},
+	}
+
+	object = object.Set(vdf.Pair{Key: "AppID", String: "233780"})
+	object = object.Set(vdf.Pair{Key: "NeedsUpdate", String: "0"})
+	assert.Equal(t, vdf.Object{
+		{Key: "AppID", String: "233780"},
+		{Key: "WorkshopItemsInstalled", Object: vdf.Object{}},
+		{Key: "NeedsUpdate", String: "0"},
+	}, object)
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{input: "\"a\"\n{\n\t\"b\"\t\"c\"\n", line: 4, column: 1},
+		{input: "\"a\"\n{\n\t\"b\"\n}", line: 4, column: 1},
+		{input: "\"a\"\t\"b\"\n}", line: 2, column: 1},
+		{input: "\"a\"\n{\n\t\"b\t\"c\"\n}", line: 3, column: 7},
+		{input: "{", line: 1, column: 1},
+	}
+
+	for _, test := range tests {
+		_, err := vdf.ParseBytes([]byte(test.input))
+		var syntaxErr *vdf.SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr, test.input) {
+			assert.Equal(t, test.line, syntaxErr.Line, test.input)
+			assert.Equal(t, test.column, syntaxErr.Column, test.input)
+		}
+	}
+}
+
+func TestWriteTo_Roundtrip(t *testing.T) {
+	original, err := os.ReadFile("testdata/appworkshop_107410.acf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := vdf.ParseBytes(original)
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(vdf.Marshal(object)))
+
+	object = vdf.Object{{Key: "escaped", String: "a \"quoted\"\tpath\\\n"}}
+	var buf bytes.Buffer
+	n, err := object.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	parsed, err := vdf.Parse(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, object, parsed)
+}
+
+type appWorkshop struct {
+	AppId                  int `vdf:"appid"`
+	SizeOnDisk             uint64
+	NeedsUpdate            bool
+	WorkshopItemsInstalled map[string]struct {
+		Size     int64
+		Manifest string
+	}
+	WorkshopItemDetails vdf.Object
+	Ignored             string `vdf:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	data, err := os.ReadFile("testdata/appworkshop_107410.acf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest struct {
+		AppWorkshop *appWorkshop
+	}
+	err = vdf.Unmarshal(data, &manifest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 107410, manifest.AppWorkshop.AppId)
+	assert.Equal(t, uint64(1048576), manifest.AppWorkshop.SizeOnDisk)
+	assert.False(t, manifest.AppWorkshop.NeedsUpdate)
+	assert.Len(t, manifest.AppWorkshop.WorkshopItemsInstalled, 2)
+	assert.Equal(t, int64(524288), manifest.AppWorkshop.WorkshopItemsInstalled["450814997"].Size)
+	assert.Equal(
+		t,
+		"1271470419227340823",
+		manifest.AppWorkshop.WorkshopItemsInstalled["463939057"].Manifest,
+	)
+	assert.Equal(t, vdf.Object{}, manifest.AppWorkshop.WorkshopItemDetails)
+
+	var generic map[string]any
+	err = vdf.Unmarshal([]byte(`"a" { "b" "c" }`), &generic)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": "c"}}, generic)
+}
+
+func TestUnmarshal_DecodeError(t *testing.T) {
+	var manifest struct {
+		AppWorkshop appWorkshop
+	}
+	err := vdf.UnmarshalRead(strings.NewReader(`"AppWorkshop" { "appid" "abc" }`), &manifest)
+	var decodeErr *vdf.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, []string{"AppWorkshop", "appid"}, decodeErr.Path)
+	}
+
+	err = vdf.Unmarshal([]byte(`"AppWorkshop" "string"`), &manifest)
+	assert.ErrorAs(t, err, &decodeErr)
+}
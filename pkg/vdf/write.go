@@ -0,0 +1,61 @@
+package vdf
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+// WriteTo writes the object in the format used by Steam, with each key on a separate line and
+// nested objects indented with tabs.
+func (o Object) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	writeObject(bw, o, 0)
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Marshal returns the object in the format used by Steam. See [Object.WriteTo].
+func Marshal(o Object) []byte {
+	var buf bytes.Buffer
+	_, _ = o.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+func writeObject(w *bufio.Writer, o Object, depth int) {
+	indent := strings.Repeat("\t", depth)
+	for _, pair := range o {
+		w.WriteString(indent)
+		writeQuoted(w, pair.Key)
+		if pair.IsObject() {
+			w.WriteString("\n" + indent + "{\n")
+			writeObject(w, pair.Object, depth+1)
+			w.WriteString(indent + "}\n")
+		} else {
+			w.WriteString("\t\t")
+			writeQuoted(w, pair.String)
+			w.WriteString("\n")
+		}
+	}
+}
+
+func writeQuoted(w *bufio.Writer, s string) {
+	w.WriteByte('"')
+	escaper.WriteString(w, s)
+	w.WriteByte('"')
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}